@@ -46,7 +46,7 @@ func (im *InMemory) Set(_ context.Context, data Data, duration time.Duration) er
 	return nil
 }
 
-func (im *InMemory) Get(ctx context.Context, key Key, dest any) error {
+func (im *InMemory) Get(_ context.Context, key Key, dest any) error {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
@@ -56,10 +56,8 @@ func (im *InMemory) Get(ctx context.Context, key Key, dest any) error {
 	}
 
 	if result.TTL.Before(time.Now()) {
-		err := im.Delete(ctx, key)
-		if err != nil {
-			return err
-		}
+		// im.mu is held, Delete would dead lock
+		delete(im.data, key)
 		return ErrInMemExpired
 	}
 
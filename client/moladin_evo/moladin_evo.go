@@ -17,7 +17,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-var ErrHealthCheck = errors.New("failed health check moladin-evo")
+var (
+	ErrHealthCheck = errors.New("failed health check moladin-evo")
+	// ErrInvalidToken is returned by UserDetail when moladin evo reject the
+	// token with 401 or 403.
+	ErrInvalidToken = errors.New("invalid token")
+)
 
 type IMoladinEvo interface {
 	Health(ctx context.Context) error
@@ -103,7 +108,7 @@ func (c *MoladinEvoPackage) UserDetail(ctx context.Context, token string) (actLo
 	}
 
 	var res Response
-	_, _, err := c.client.Clone().Get(fmt.Sprintf("%s/%s", c.BaseURL, "crm/account/user-management/detail")).
+	resp, _, err := c.client.Clone().Get(fmt.Sprintf("%s/%s", c.BaseURL, "crm/account/user-management/detail")).
 		Set(constant.XRequestIdHeader, commonContext.GetValueAsString(ctx, constant.XRequestIdHeader)).
 		Set(constant.XServiceNameHeader, c.XServicesName).
 		Set("authorization", token).
@@ -113,6 +118,10 @@ func (c *MoladinEvoPackage) UserDetail(ctx context.Context, token string) (actLo
 		logger.Error(ctx, "fail get user", err[0], logger.Tag{Key: "logCtx", Value: logCtx})
 	}
 
+	if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		return res.Data, fmt.Errorf("%w: %s", ErrInvalidToken, res.Message)
+	}
+
 	if !res.Success {
 		return res.Data, errors.New(res.Message)
 	}
@@ -2,6 +2,9 @@ package moladin_evo_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"bitbucket.org/moladinTech/go-lib-common/client/moladin_evo"
@@ -89,3 +92,68 @@ func TestUserDetail_ErrorOnClientGetUrlUser(t *testing.T) {
 
 	})
 }
+
+func TestUserDetail_ErrorInvalidToken(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			statusCode int
+		}
+		want struct {
+			isInvalidToken bool
+		}
+		testCase struct {
+			name string
+			args args
+			want want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Unauthorized",
+			args: args{statusCode: http.StatusUnauthorized},
+			want: want{isInvalidToken: true},
+		},
+		{
+			name: "Forbidden",
+			args: args{statusCode: http.StatusForbidden},
+			want: want{isInvalidToken: true},
+		},
+		{
+			name: "Internal Server Error",
+			args: args{statusCode: http.StatusInternalServerError},
+			want: want{isInvalidToken: false},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.args.statusCode)
+				_, _ = w.Write([]byte(`{"success":false,"message":"failed"}`))
+			}))
+			t.Cleanup(server.Close)
+
+			span := sentry.Span{}
+			mockSentry := sentryMock.NewISentry(t)
+			mockSentry.On("StartSpan", mock.Anything, mock.Anything).
+				Return(&span).
+				Once()
+
+			moladinEvo := moladin_evo.NewMoladinEvo(
+				validator.New(),
+				moladin_evo.WithSentry(mockSentry),
+				moladin_evo.WithBaseUrl(server.URL),
+				moladin_evo.WithServicesName("dummy"),
+			)
+
+			_, err := moladinEvo.UserDetail(context.TODO(), "token")
+			require.Error(t, err)
+			require.Equal(t, tc.want.isInvalidToken, errors.Is(err, moladin_evo.ErrInvalidToken))
+		})
+	}
+}
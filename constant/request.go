@@ -9,6 +9,7 @@ var (
 	XServiceNameHeader      = textproto.CanonicalMIMEHeaderKey("x-service-name")
 	XUserType               = textproto.CanonicalMIMEHeaderKey("x-user-type")
 	XUserDetail             = textproto.CanonicalMIMEHeaderKey("x-user-detail")
+	XTenantId               = textproto.CanonicalMIMEHeaderKey("x-tenant-id")
 	XApiKeyHeader           = textproto.CanonicalMIMEHeaderKey("x-api-key")
 	AuthorizationHeader     = textproto.CanonicalMIMEHeaderKey("authorization")
 	ContextBackground       = textproto.CanonicalMIMEHeaderKey("ContextBackground")
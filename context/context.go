@@ -2,6 +2,13 @@ package context
 
 import (
 	"context"
+
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+)
+
+type (
+	tenantScopeSkippedKey struct{}
+	softDeletedKey        struct{}
 )
 
 func GetValueAsString(ctx context.Context, key string) string {
@@ -12,3 +19,42 @@ func GetValueAsString(ctx context.Context, key string) string {
 
 	return ""
 }
+
+// WithTenant return a copy of ctx carrying the tenant, e.g. the dealer or
+// branch id of the user.
+func WithTenant(ctx context.Context, tenantID any) context.Context {
+	return context.WithValue(ctx, constant.XTenantId, tenantID)
+}
+
+// GetTenant return the tenant carried by ctx.
+func GetTenant(ctx context.Context) (any, bool) {
+	tenantID := ctx.Value(constant.XTenantId)
+
+	return tenantID, tenantID != nil
+}
+
+// WithoutTenantScope return a copy of ctx whose queries aren't scoped to
+// the tenant, only use it for admin operations across tenants.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantScopeSkippedKey{}, true)
+}
+
+// IsTenantScopeSkipped report whether ctx come from WithoutTenantScope.
+func IsTenantScopeSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(tenantScopeSkippedKey{}).(bool)
+
+	return skipped
+}
+
+// WithSoftDeleted return a copy of ctx whose queries include the soft
+// deleted rows.
+func WithSoftDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, softDeletedKey{}, true)
+}
+
+// IsSoftDeletedIncluded report whether ctx come from WithSoftDeleted.
+func IsSoftDeletedIncluded(ctx context.Context) bool {
+	included, _ := ctx.Value(softDeletedKey{}).(bool)
+
+	return included
+}
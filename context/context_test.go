@@ -30,3 +30,30 @@ func TestContext_ErrorOnTypeValue(t *testing.T) {
 		require.NotEqual(t, reflect.TypeOf(val), reflect.TypeOf(actualVal))
 	})
 }
+
+func TestContext_Tenant(t *testing.T) {
+	t.Parallel()
+	t.Run("Should Succeed Tenant", func(t *testing.T) {
+		ctx := context.TODO()
+		_, ok := commonContext.GetTenant(ctx)
+		require.False(t, ok)
+		require.False(t, commonContext.IsTenantScopeSkipped(ctx))
+
+		ctx = commonContext.WithTenant(ctx, int64(12))
+		tenantID, ok := commonContext.GetTenant(ctx)
+		require.True(t, ok)
+		require.Equal(t, int64(12), tenantID)
+
+		ctx = commonContext.WithoutTenantScope(ctx)
+		require.True(t, commonContext.IsTenantScopeSkipped(ctx))
+	})
+}
+
+func TestContext_SoftDeleted(t *testing.T) {
+	t.Parallel()
+	t.Run("Should Succeed Soft Deleted", func(t *testing.T) {
+		ctx := context.TODO()
+		require.False(t, commonContext.IsSoftDeletedIncluded(ctx))
+		require.True(t, commonContext.IsSoftDeletedIncluded(commonContext.WithSoftDeleted(ctx)))
+	})
+}
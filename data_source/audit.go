@@ -0,0 +1,220 @@
+package data_source
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	actLogModel "bitbucket.org/moladinTech/go-lib-activity-log/model"
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
+	commonTime "bitbucket.org/moladinTech/go-lib-common/time"
+	rbacModel "bitbucket.org/moladinTech/go-lib-rbac/model"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	AUDIT_CREATED_AT_COLUMN = "created_at"
+	AUDIT_CREATED_BY_COLUMN = "created_by"
+	AUDIT_UPDATED_AT_COLUMN = "updated_at"
+	AUDIT_UPDATED_BY_COLUMN = "updated_by"
+	AUDIT_DELETED_AT_COLUMN = "deleted_at"
+
+	// AUDIT_DEFAULT_USER is used when the context doesn't carry any user,
+	// e.g. consumers and cron jobs.
+	AUDIT_DEFAULT_USER = "system"
+)
+
+// AuditColumns is embedded in the models having the audit columns, the
+// columns are flattened by the struct helpers.
+type AuditColumns struct {
+	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
+	CreatedBy string     `db:"created_by" json:"createdBy"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updatedAt"`
+	UpdatedBy string     `db:"updated_by" json:"updatedBy"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
+}
+
+// Audit fill the audit columns from the time and the user in context, and
+// exclude the soft deleted rows from the queries.
+type Audit struct {
+	time commonTime.TimeItf
+	user func(ctx context.Context) string
+}
+
+type AuditOption func(*Audit)
+
+// WithAuditTime set the time source, default time.InitTime().
+func WithAuditTime(time commonTime.TimeItf) AuditOption {
+	return func(a *Audit) {
+		a.time = time
+	}
+}
+
+// WithAuditUser set the user of the created_by and updated_by columns,
+// default AuditUser.
+func WithAuditUser(user func(ctx context.Context) string) AuditOption {
+	return func(a *Audit) {
+		a.user = user
+	}
+}
+
+func NewAudit(opts ...AuditOption) *Audit {
+	audit := &Audit{
+		time: commonTime.InitTime(),
+		user: AuditUser,
+	}
+	for _, opt := range opts {
+		opt(audit)
+	}
+
+	return audit
+}
+
+// AuditUser return the email of the constant.XUserDetail user set by the
+// auth middleware, or AUDIT_DEFAULT_USER.
+func AuditUser(ctx context.Context) string {
+	var email string
+	switch user := ctx.Value(constant.XUserDetail).(type) {
+	case actLogModel.UserDetail:
+		email = user.Email
+	case *actLogModel.UserDetail:
+		if user != nil {
+			email = user.Email
+		}
+	case rbacModel.Me:
+		email = user.Email
+	case *rbacModel.Me:
+		if user != nil {
+			email = user.Email
+		}
+	}
+
+	if email == "" {
+		return AUDIT_DEFAULT_USER
+	}
+
+	return email
+}
+
+// Insert set created_at, created_by, updated_at and updated_by of every
+// inserted row. It must be called after Columns/Values or SetMap.
+func (a *Audit) Insert(ctx context.Context, query sq.InsertBuilder) sq.InsertBuilder {
+	now, user := a.time.Now(), a.user(ctx)
+
+	query = setInsertColumn(query, AUDIT_CREATED_AT_COLUMN, now)
+	query = setInsertColumn(query, AUDIT_CREATED_BY_COLUMN, user)
+	query = setInsertColumn(query, AUDIT_UPDATED_AT_COLUMN, now)
+	return setInsertColumn(query, AUDIT_UPDATED_BY_COLUMN, user)
+}
+
+// Update set updated_at and updated_by.
+func (a *Audit) Update(ctx context.Context, query sq.UpdateBuilder) sq.UpdateBuilder {
+	return query.
+		Set(AUDIT_UPDATED_AT_COLUMN, a.time.Now()).
+		Set(AUDIT_UPDATED_BY_COLUMN, a.user(ctx))
+}
+
+// SoftDelete return UPDATE table SET deleted_at = ?, updated_at = ?,
+// updated_by = ? for the not yet deleted rows, add the where clause of the
+// deleted rows to it.
+func (a *Audit) SoftDelete(ctx context.Context, table string) sq.UpdateBuilder {
+	now := a.time.Now()
+
+	return sq.Update(table).
+		Set(AUDIT_DELETED_AT_COLUMN, now).
+		Set(AUDIT_UPDATED_AT_COLUMN, now).
+		Set(AUDIT_UPDATED_BY_COLUMN, a.user(ctx)).
+		Where(sq.Eq{AUDIT_DELETED_AT_COLUMN: nil})
+}
+
+// Select add deleted_at IS NULL to the where clause, unless ctx come from
+// context.WithSoftDeleted. Qualify the column with the table alias for
+// joins, e.g. Select(ctx, query, "la").
+func (a *Audit) Select(ctx context.Context, query sq.SelectBuilder, tableAlias ...string) sq.SelectBuilder {
+	if commonContext.IsSoftDeletedIncluded(ctx) {
+		return query
+	}
+
+	column := AUDIT_DELETED_AT_COLUMN
+	if len(tableAlias) > 0 && tableAlias[0] != "" {
+		column = tableAlias[0] + "." + column
+	}
+
+	return query.Where(sq.Eq{column: nil})
+}
+
+// Created set the created and updated audit fields of data, a pointer to
+// struct or a slice of structs, before Update or BulkInsert.
+func (a *Audit) Created(ctx context.Context, data any) {
+	now, user := a.time.Now(), a.user(ctx)
+
+	setAuditFields(data, map[string]any{
+		AUDIT_CREATED_AT_COLUMN: now,
+		AUDIT_CREATED_BY_COLUMN: user,
+		AUDIT_UPDATED_AT_COLUMN: now,
+		AUDIT_UPDATED_BY_COLUMN: user,
+	})
+}
+
+// Updated set the updated audit fields of data, a pointer to struct or a
+// slice of structs, before Update.
+func (a *Audit) Updated(ctx context.Context, data any) {
+	setAuditFields(data, map[string]any{
+		AUDIT_UPDATED_AT_COLUMN: a.time.Now(),
+		AUDIT_UPDATED_BY_COLUMN: a.user(ctx),
+	})
+}
+
+// setAuditFields set the fields of data tagged with the columns, the
+// fields having another type are left untouched.
+func setAuditFields(data any, values map[string]any) {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	var rows []reflect.Value
+	switch rv.Kind() {
+	case reflect.Struct:
+		rows = append(rows, rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			row := rv.Index(i)
+			if row.Kind() == reflect.Pointer {
+				row = row.Elem()
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	for _, row := range rows {
+		if !row.CanSet() || row.Kind() != reflect.Struct {
+			continue
+		}
+
+		for _, field := range structDbFields(row.Type()) {
+			value, ok := values[field.Column]
+			if !ok {
+				continue
+			}
+
+			fieldValue := row.FieldByIndex(field.Index)
+			setAuditField(fieldValue, reflect.ValueOf(value))
+		}
+	}
+}
+
+func setAuditField(field reflect.Value, value reflect.Value) {
+	switch {
+	case value.Type().AssignableTo(field.Type()):
+		field.Set(value)
+	case field.Kind() == reflect.Pointer && value.Type().AssignableTo(field.Type().Elem()):
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().Set(value)
+		field.Set(ptr)
+	case value.Type().ConvertibleTo(field.Type()):
+		field.Set(value.Convert(field.Type()))
+	}
+}
@@ -0,0 +1,134 @@
+package data_source_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	actLogModel "bitbucket.org/moladinTech/go-lib-activity-log/model"
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	timeMock "bitbucket.org/moladinTech/go-lib-common/time/mocks"
+	rbacModel "bitbucket.org/moladinTech/go-lib-rbac/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+)
+
+type auditedLoan struct {
+	Id     int    `db:"id"`
+	Status string `db:"status"`
+	commonDataSource.AuditColumns
+}
+
+func Test_Audit(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			ctx   context.Context
+			build func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer
+		}
+		want struct {
+			query string
+			args  []any
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	userCtx := context.WithValue(context.Background(), constant.XUserDetail, actLogModel.UserDetail{Email: "john@moladin.com"})
+
+	testCases := []testCase{
+		{
+			name: "Insert",
+			args: args{ctx: userCtx, build: func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer {
+				return audit.Insert(ctx, sq.Insert("loans").Columns("status").Values("new"))
+			}},
+			want: want{
+				query: "INSERT INTO loans (status,created_at,created_by,updated_at,updated_by) VALUES (?,?,?,?,?)",
+				args:  []any{"new", now, "john@moladin.com", now, "john@moladin.com"},
+			},
+		},
+		{
+			name: "Update",
+			args: args{ctx: context.Background(), build: func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer {
+				return audit.Update(ctx, sq.Update("loans").Set("status", "approved").Where(sq.Eq{"id": 1}))
+			}},
+			want: want{
+				query: "UPDATE loans SET status = ?, updated_at = ?, updated_by = ? WHERE id = ?",
+				args:  []any{"approved", now, commonDataSource.AUDIT_DEFAULT_USER, 1},
+			},
+		},
+		{
+			name: "SoftDelete",
+			args: args{ctx: userCtx, build: func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer {
+				return audit.SoftDelete(ctx, "loans").Where(sq.Eq{"id": 1})
+			}},
+			want: want{
+				query: "UPDATE loans SET deleted_at = ?, updated_at = ?, updated_by = ? WHERE deleted_at IS NULL AND id = ?",
+				args:  []any{now, now, "john@moladin.com", 1},
+			},
+		},
+		{
+			name: "Select",
+			args: args{ctx: userCtx, build: func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer {
+				return audit.Select(ctx, sq.Select("l.id").From("loans l"), "l")
+			}},
+			want: want{
+				query: "SELECT l.id FROM loans l WHERE l.deleted_at IS NULL",
+			},
+		},
+		{
+			name: "SelectWithSoftDeleted",
+			args: args{ctx: commonContext.WithSoftDeleted(userCtx), build: func(ctx context.Context, audit *commonDataSource.Audit) sq.Sqlizer {
+				return audit.Select(ctx, sq.Select("id").From("loans"))
+			}},
+			want: want{
+				query: "SELECT id FROM loans",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			timeItf := timeMock.NewTimeItf(t)
+			timeItf.On("Now").Return(now).Maybe()
+			audit := commonDataSource.NewAudit(commonDataSource.WithAuditTime(timeItf))
+
+			query, args, err := testCase.args.build(testCase.args.ctx, audit).ToSql()
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.query, query)
+			assert.Equal(t, testCase.want.args, args)
+		})
+	}
+}
+
+func Test_AuditFields(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	timeItf := timeMock.NewTimeItf(t)
+	timeItf.On("Now").Return(now)
+	audit := commonDataSource.NewAudit(commonDataSource.WithAuditTime(timeItf))
+	ctx := context.WithValue(context.Background(), constant.XUserDetail, &rbacModel.Me{Email: "jane@moladin.com"})
+
+	loans := []auditedLoan{{Id: 1}, {Id: 2}}
+	audit.Created(ctx, loans)
+	for _, loan := range loans {
+		assert.Equal(t, now, loan.CreatedAt)
+		assert.Equal(t, "jane@moladin.com", loan.CreatedBy)
+		assert.Equal(t, now, loan.UpdatedAt)
+		assert.Equal(t, "jane@moladin.com", loan.UpdatedBy)
+		assert.Nil(t, loan.DeletedAt)
+	}
+
+	loan := &auditedLoan{Id: 1}
+	audit.Updated(context.Background(), loan)
+	assert.True(t, loan.CreatedAt.IsZero())
+	assert.Equal(t, now, loan.UpdatedAt)
+	assert.Equal(t, commonDataSource.AUDIT_DEFAULT_USER, loan.UpdatedBy)
+}
@@ -0,0 +1,214 @@
+package data_source
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// BULK_INSERT_MAX_PLACEHOLDERS is the placeholder limit of a single
+	// statement on postgres and mysql.
+	BULK_INSERT_MAX_PLACEHOLDERS = 65535
+)
+
+type bulkInsertOption struct {
+	excludedColumns []string
+	conflictColumns []string
+	updateColumns   []string
+	onConflict      bool
+	chunkSize       int
+	useCopy         bool
+}
+
+type BulkInsertOption func(*bulkInsertOption)
+
+// WithBulkExcludedColumns skip the columns, e.g. the generated id.
+func WithBulkExcludedColumns(columns ...string) BulkInsertOption {
+	return func(o *bulkInsertOption) {
+		o.excludedColumns = append(o.excludedColumns, columns...)
+	}
+}
+
+// WithOnConflict upsert the rows, updateColumns are set from the inserted
+// row when a row conflict with conflictColumns (postgres only, mysql use
+// the unique keys). Without updateColumns the conflicting rows are ignored,
+// on postgres conflictColumns can then be empty to ignore any conflict.
+func WithOnConflict(conflictColumns []string, updateColumns ...string) BulkInsertOption {
+	return func(o *bulkInsertOption) {
+		o.onConflict = true
+		o.conflictColumns = conflictColumns
+		o.updateColumns = updateColumns
+	}
+}
+
+// WithChunkSize set the rows per statement, by default as many rows as
+// BULK_INSERT_MAX_PLACEHOLDERS allows.
+func WithChunkSize(chunkSize int) BulkInsertOption {
+	return func(o *bulkInsertOption) {
+		o.chunkSize = chunkSize
+	}
+}
+
+// WithCopy use COPY FROM STDIN instead of INSERT, postgres only and it
+// can't be used with WithOnConflict. COPY require the lib/pq driver, the
+// pgx driver falls back to INSERT.
+func WithCopy() BulkInsertOption {
+	return func(o *bulkInsertOption) {
+		o.useCopy = true
+	}
+}
+
+// BulkInsert insert rows into table in chunks of multi-row INSERT, the
+// columns are taken from the db tags of T. db is either *sqlx.DB or
+// *sqlx.Tx, with *sqlx.DB each chunk is committed on its own. It returns
+// the total rows affected. Invalid options return errors.ErrBulkInsert.
+func BulkInsert[T any](ctx context.Context, db sqlx.ExtContext, table string, rows []T, opts ...BulkInsertOption) (int64, error) {
+	option := &bulkInsertOption{}
+	for _, opt := range opts {
+		opt(option)
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	fields := structDbFields(reflect.TypeOf(rows).Elem(), option.excludedColumns...)
+	columns := dbFieldColumns(fields)
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("%w: no db column found in %T", commonErrors.ErrBulkInsert, rows)
+	}
+
+	isPostgres := isPostgresDriver(db.DriverName())
+	if isPostgres && len(option.updateColumns) > 0 && len(option.conflictColumns) == 0 {
+		return 0, fmt.Errorf("%w: ON CONFLICT DO UPDATE require the conflict columns on postgres", commonErrors.ErrBulkInsert)
+	}
+	if option.useCopy {
+		if !isPostgres || option.onConflict {
+			return 0, fmt.Errorf("%w: COPY is only supported on postgres without conflict handling", commonErrors.ErrBulkInsert)
+		}
+		// pq.CopyIn only works through lib/pq
+		if db.DriverName() == DriverPostgres {
+			return bulkCopy(ctx, db, table, columns, fields, rows)
+		}
+	}
+
+	chunkSize := option.chunkSize
+	if chunkSize <= 0 || chunkSize*len(columns) > BULK_INSERT_MAX_PLACEHOLDERS {
+		chunkSize = BULK_INSERT_MAX_PLACEHOLDERS / len(columns)
+	}
+
+	var rowsAffected int64
+	for start := 0; start < len(rows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		query, args, err := bulkInsertQuery(table, columns, fields, rows[start:end], option, isPostgres)
+		if err != nil {
+			return rowsAffected, fmt.Errorf("%w: %s", commonErrors.ErrBulkInsert, err.Error())
+		}
+
+		affected, err := execWithHooks(ctx, db, query, args)
+		if err != nil {
+			return rowsAffected, fmt.Errorf("rows[%d:%d]: %w", start, end, err)
+		}
+		rowsAffected += affected
+	}
+
+	return rowsAffected, nil
+}
+
+func bulkInsertQuery[T any](table string, columns []string, fields []dbField, rows []T, option *bulkInsertOption, isPostgres bool) (string, []any, error) {
+	builder := sq.Insert(table).Columns(columns...)
+	if isPostgres {
+		builder = builder.PlaceholderFormat(sq.Dollar)
+	}
+
+	for _, row := range rows {
+		builder = builder.Values(dbFieldValues(reflect.ValueOf(row), fields)...)
+	}
+
+	if option.onConflict {
+		switch {
+		case !isPostgres && len(option.updateColumns) == 0:
+			builder = builder.Options("IGNORE")
+		case !isPostgres:
+			sets := make([]string, 0, len(option.updateColumns))
+			for _, column := range option.updateColumns {
+				sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", column, column))
+			}
+			builder = builder.Suffix("ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "))
+		case len(option.updateColumns) == 0 && len(option.conflictColumns) == 0:
+			builder = builder.Suffix("ON CONFLICT DO NOTHING")
+		case len(option.updateColumns) == 0:
+			builder = builder.Suffix(fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(option.conflictColumns, ", ")))
+		default:
+			sets := make([]string, 0, len(option.updateColumns))
+			for _, column := range option.updateColumns {
+				sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+			}
+			builder = builder.Suffix(fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s",
+				strings.Join(option.conflictColumns, ", "), strings.Join(sets, ", ")))
+		}
+	}
+
+	return builder.ToSql()
+}
+
+// bulkCopy stream the rows with COPY FROM STDIN in a transaction, the one
+// of db when it's a *sqlx.Tx.
+func bulkCopy[T any](ctx context.Context, db sqlx.ExtContext, table string, columns []string, fields []dbField, rows []T) (int64, error) {
+	tx, isTx := db.(*sqlx.Tx)
+	if !isTx {
+		sqlxDB, ok := db.(*sqlx.DB)
+		if !ok {
+			return 0, fmt.Errorf("%w: COPY require *sqlx.DB or *sqlx.Tx", commonErrors.ErrBulkInsert)
+		}
+
+		var err error
+		tx, err = sqlxDB.BeginTxx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+	}
+
+	query := pq.CopyIn(table, columns...)
+	if schema, name, found := strings.Cut(table, "."); found {
+		query = pq.CopyInSchema(schema, name, columns...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for i, row := range rows {
+		if _, err = stmt.ExecContext(ctx, dbFieldValues(reflect.ValueOf(row), fields)...); err != nil {
+			return 0, fmt.Errorf("rows[%d]: %w", i, err)
+		}
+	}
+
+	// flush the buffered rows
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return 0, err
+	}
+
+	if !isTx {
+		if err = tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(rows)), nil
+}
@@ -0,0 +1,197 @@
+package data_source_test
+
+import (
+	"context"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type bulkAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type bulkUser struct {
+	Id     int    `db:"id"`
+	Name   string `db:"name"`
+	Email  string `db:"email"`
+	Secret string `db:"-"`
+	bulkAudit
+}
+
+func mockSqlxDriver(driverName string) (*sqlx.DB, sqlmock.Sqlmock) {
+	dbmock, queryMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		return nil, queryMock
+	}
+
+	return sqlx.NewDb(dbmock, driverName), queryMock
+}
+
+func Test_BulkInsert(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			driverName string
+			opts       []commonDataSource.BulkInsertOption
+		}
+		want struct {
+			queries []string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Postgres chunked insert",
+			args: args{
+				driverName: "postgres",
+				opts: []commonDataSource.BulkInsertOption{
+					commonDataSource.WithBulkExcludedColumns("id"),
+					commonDataSource.WithChunkSize(2),
+				},
+			},
+			want: want{queries: []string{
+				"INSERT INTO users (name,email,created_by) VALUES ($1,$2,$3),($4,$5,$6)",
+				"INSERT INTO users (name,email,created_by) VALUES ($1,$2,$3)",
+			}},
+		},
+		{
+			name: "Postgres upsert",
+			args: args{
+				driverName: "postgres",
+				opts: []commonDataSource.BulkInsertOption{
+					commonDataSource.WithOnConflict([]string{"email"}, "name"),
+				},
+			},
+			want: want{queries: []string{
+				"INSERT INTO users (id,name,email,created_by) VALUES ($1,$2,$3,$4),($5,$6,$7,$8),($9,$10,$11,$12) " +
+					"ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+			}},
+		},
+		{
+			name: "Postgres ignore any conflict",
+			args: args{
+				driverName: "postgres",
+				opts: []commonDataSource.BulkInsertOption{
+					commonDataSource.WithOnConflict(nil),
+				},
+			},
+			want: want{queries: []string{
+				"INSERT INTO users (id,name,email,created_by) VALUES ($1,$2,$3,$4),($5,$6,$7,$8),($9,$10,$11,$12) " +
+					"ON CONFLICT DO NOTHING",
+			}},
+		},
+		{
+			name: "Mysql upsert",
+			args: args{
+				driverName: "mysql",
+				opts: []commonDataSource.BulkInsertOption{
+					commonDataSource.WithOnConflict(nil, "name"),
+				},
+			},
+			want: want{queries: []string{
+				"INSERT INTO users (id,name,email,created_by) VALUES (?,?,?,?),(?,?,?,?),(?,?,?,?) " +
+					"ON DUPLICATE KEY UPDATE name = VALUES(name)",
+			}},
+		},
+		{
+			name: "Mysql ignore conflict",
+			args: args{
+				driverName: "mysql",
+				opts: []commonDataSource.BulkInsertOption{
+					commonDataSource.WithOnConflict(nil),
+				},
+			},
+			want: want{queries: []string{
+				"INSERT IGNORE INTO users (id,name,email,created_by) VALUES (?,?,?,?),(?,?,?,?),(?,?,?,?)",
+			}},
+		},
+	}
+
+	rows := []bulkUser{
+		{Id: 1, Name: "John", Email: "john@mail.com", bulkAudit: bulkAudit{CreatedBy: "admin"}},
+		{Id: 2, Name: "Jane", Email: "jane@mail.com", bulkAudit: bulkAudit{CreatedBy: "admin"}},
+		{Id: 3, Name: "Doe", Email: "doe@mail.com", bulkAudit: bulkAudit{CreatedBy: "admin"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbmock, queryMock := mockSqlxDriver(testCase.args.driverName)
+			for _, query := range testCase.want.queries {
+				queryMock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			rowsAffected, err := commonDataSource.BulkInsert(context.Background(), dbmock, "users", rows, testCase.args.opts...)
+
+			assert.Nil(t, err)
+			assert.Equal(t, int64(len(testCase.want.queries)), rowsAffected)
+			assert.Nil(t, queryMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_BulkInsertUpsertWithoutConflictColumns(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver("postgres")
+
+	_, err := commonDataSource.BulkInsert(context.Background(), dbmock, "users", []bulkUser{{Id: 1}},
+		commonDataSource.WithOnConflict(nil, "name"))
+
+	assert.ErrorIs(t, err, commonErrors.ErrBulkInsert)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_BulkInsertCopyNotSupported(t *testing.T) {
+	t.Parallel()
+	dbmock, _ := mockSqlxDriver("mysql")
+
+	_, err := commonDataSource.BulkInsert(context.Background(), dbmock, "users", []bulkUser{{Id: 1}}, commonDataSource.WithCopy())
+
+	assert.ErrorIs(t, err, commonErrors.ErrBulkInsert)
+}
+
+func Test_BulkInsertCopy(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver("postgres")
+	queryMock.ExpectBegin()
+	copyPrepare := queryMock.ExpectPrepare(`COPY "users" ("name", "email") FROM STDIN`)
+	copyPrepare.ExpectExec().WithArgs("John", "john@mail.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	copyPrepare.ExpectExec().WithArgs("Jane", "jane@mail.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	copyPrepare.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 2))
+	queryMock.ExpectCommit()
+
+	rowsAffected, err := commonDataSource.BulkInsert(context.Background(), dbmock, "users",
+		[]bulkUser{{Name: "John", Email: "john@mail.com"}, {Name: "Jane", Email: "jane@mail.com"}},
+		commonDataSource.WithCopy(), commonDataSource.WithBulkExcludedColumns("id", "created_by"),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rowsAffected)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_BulkInsertCopyPgxFallback(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver(commonDataSource.DriverPgx)
+	queryMock.ExpectExec("INSERT INTO users (name,email) VALUES ($1,$2),($3,$4)").
+		WithArgs("John", "john@mail.com", "Jane", "jane@mail.com").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	rowsAffected, err := commonDataSource.BulkInsert(context.Background(), dbmock, "users",
+		[]bulkUser{{Name: "John", Email: "john@mail.com"}, {Name: "Jane", Email: "jane@mail.com"}},
+		commonDataSource.WithCopy(), commonDataSource.WithBulkExcludedColumns("id", "created_by"),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rowsAffected)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
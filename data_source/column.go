@@ -0,0 +1,150 @@
+package data_source
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lann/builder"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// TagOptionPk mark the primary key columns used by Update, e.g.
+	// `db:"id,pk"`. Without it the column id is used.
+	TagOptionPk = "pk"
+	// TagOptionVersion mark the optimistic locking column used by Update,
+	// e.g. `db:"version,version"`.
+	TagOptionVersion = "version"
+)
+
+// dbField is a struct field having a db tag.
+type dbField struct {
+	Column  string
+	Index   []int
+	Options []string
+}
+
+func (f dbField) hasOption(option string) bool {
+	return slices.Contains(f.Options, option)
+}
+
+// structDbFields return the db tagged fields of the struct rt, embedded
+// structs without db tag are flattened.
+func structDbFields(rt reflect.Type, excluded ...string) []dbField {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	fields := make([]dbField, 0)
+	if rt.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := strings.Split(field.Tag.Get("db"), ",")
+		column := tag[0]
+
+		if column == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for _, embedded := range structDbFields(field.Type, excluded...) {
+				embedded.Index = append([]int{i}, embedded.Index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if column == "" || column == "-" || !field.IsExported() || slices.Contains(excluded, column) {
+			continue
+		}
+		fields = append(fields, dbField{Column: column, Index: []int{i}, Options: tag[1:]})
+	}
+
+	return fields
+}
+
+func dbFieldColumns(fields []dbField) []string {
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, field.Column)
+	}
+
+	return columns
+}
+
+func dbFieldValues(rv reflect.Value, fields []dbField) []any {
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	values := make([]any, 0, len(fields))
+	for _, field := range fields {
+		values = append(values, rv.FieldByIndex(field.Index).Interface())
+	}
+
+	return values
+}
+
+func isPostgresDriver(driverName string) bool {
+	return driverName == DriverPostgres || driverName == DriverPgx
+}
+
+// execWithHooks execute query on db or tx, with the query hooks of db when
+// it's a *sqlx.DB, and return the rows affected.
+func execWithHooks(ctx context.Context, db sqlx.ExtContext, query string, args []any) (int64, error) {
+	var hooks []QueryHook
+	if sqlxDB, ok := db.(*sqlx.DB); ok {
+		hooks = getQueryHooks(sqlxDB)
+	}
+	_, inTx := db.(*sqlx.Tx)
+
+	event := &QueryEvent{Operation: QueryExec, Query: query, Args: args, InTx: inTx}
+	err := runQueryHooks(ctx, hooks, event, func(ctx context.Context) (int64, error) {
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		// not every driver support rows affected
+		rowsAffected, _ := result.RowsAffected()
+		return rowsAffected, nil
+	})
+
+	return event.RowsAffected, err
+}
+
+// setInsertColumn set column of every row inserted by query, replacing the
+// value when the column is already inserted.
+func setInsertColumn(query sq.InsertBuilder, column string, value any) sq.InsertBuilder {
+	var (
+		columns []string
+		values  [][]interface{}
+	)
+	if v, ok := builder.Get(query, "Columns"); ok {
+		columns, _ = v.([]string)
+	}
+	if v, ok := builder.Get(query, "Values"); ok {
+		values, _ = v.([][]interface{})
+	}
+
+	index := slices.Index(columns, column)
+
+	newValues := make([][]interface{}, 0, len(values))
+	for _, row := range values {
+		newRow := make([]interface{}, len(row), len(row)+1)
+		copy(newRow, row)
+		if index < 0 {
+			newRow = append(newRow, value)
+		} else if index < len(newRow) {
+			newRow[index] = value
+		}
+		newValues = append(newValues, newRow)
+	}
+
+	if index < 0 {
+		query = builder.Append(query, "Columns", column).(sq.InsertBuilder)
+	}
+
+	return builder.Set(query, "Values", newValues).(sq.InsertBuilder)
+}
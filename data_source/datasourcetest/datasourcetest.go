@@ -0,0 +1,216 @@
+package datasourcetest
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DEFAULT_DRIVER_NAME = commonDataSource.DriverPostgres
+)
+
+// Mock is a *sqlx.DB on sqlmock with expectations matching how the
+// data_source Statement pipelines run the queries. The expectations are
+// asserted when the test finish.
+type Mock struct {
+	DB      *sqlx.DB
+	Sqlmock sqlmock.Sqlmock
+
+	bypassPrepare bool
+}
+
+type options struct {
+	driverName    string
+	queryMatcher  sqlmock.QueryMatcher
+	bypassPrepare bool
+}
+
+type Option func(*options)
+
+// WithDriverName set the driver name of the *sqlx.DB, default postgres.
+func WithDriverName(driverName string) Option {
+	return func(o *options) {
+		o.driverName = driverName
+	}
+}
+
+// WithQueryMatcher set how the queries are matched, default
+// sqlmock.QueryMatcherEqual.
+func WithQueryMatcher(queryMatcher sqlmock.QueryMatcher) Option {
+	return func(o *options) {
+		o.queryMatcher = queryMatcher
+	}
+}
+
+// WithBypassPrepare enable data_source.BypassPrepare, the statements are
+// expected without prepare.
+func WithBypassPrepare() Option {
+	return func(o *options) {
+		o.bypassPrepare = true
+	}
+}
+
+func New(t testing.TB, opts ...Option) *Mock {
+	t.Helper()
+
+	o := &options{
+		driverName:   DEFAULT_DRIVER_NAME,
+		queryMatcher: sqlmock.QueryMatcherEqual,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	db, queryMock, err := sqlmock.New(sqlmock.QueryMatcherOption(o.queryMatcher))
+	if err != nil {
+		t.Fatalf("datasourcetest: failed to open sqlmock: %s", err)
+	}
+
+	sqlxDB := sqlx.NewDb(db, o.driverName)
+	if o.bypassPrepare {
+		commonDataSource.BypassPrepare(sqlxDB, true)
+	}
+
+	t.Cleanup(func() {
+		if err := queryMock.ExpectationsWereMet(); err != nil {
+			t.Errorf("datasourcetest: %s", err)
+		}
+		_ = sqlxDB.Close()
+	})
+
+	return &Mock{DB: sqlxDB, Sqlmock: queryMock, bypassPrepare: o.bypassPrepare}
+}
+
+// ExpectBegin expect data_source.ExecTx or data_source.WithTx to begin a
+// transaction.
+func (m *Mock) ExpectBegin() *Mock {
+	m.Sqlmock.ExpectBegin()
+
+	return m
+}
+
+func (m *Mock) ExpectCommit() *Mock {
+	m.Sqlmock.ExpectCommit()
+
+	return m
+}
+
+func (m *Mock) ExpectRollback() *Mock {
+	m.Sqlmock.ExpectRollback()
+
+	return m
+}
+
+// ExpectBeginError expect the transaction to fail to begin with err.
+func (m *Mock) ExpectBeginError(err error) *Mock {
+	m.Sqlmock.ExpectBegin().WillReturnError(err)
+
+	return m
+}
+
+// ExpectCommitError expect the transaction to fail to commit with err.
+func (m *Mock) ExpectCommitError(err error) *Mock {
+	m.Sqlmock.ExpectCommit().WillReturnError(err)
+
+	return m
+}
+
+// ExpectStatement expect the next statement of the pipeline to run query,
+// finish the expectation with one of the Will methods.
+func (m *Mock) ExpectStatement(query string) *StatementExpectation {
+	return &StatementExpectation{mock: m, sql: query}
+}
+
+type StatementExpectation struct {
+	mock *Mock
+	sql  string
+	args []driver.Value
+}
+
+func (e *StatementExpectation) WithArgs(args ...any) *StatementExpectation {
+	e.args = make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		e.args = append(e.args, arg)
+	}
+
+	return e
+}
+
+// WillReturnResult expect a statement without destination.
+func (e *StatementExpectation) WillReturnResult(lastInsertID, rowsAffected int64) *Mock {
+	e.expectExec().WillReturnResult(sqlmock.NewResult(lastInsertID, rowsAffected))
+
+	return e.mock
+}
+
+// WillReturnRows expect a statement with a destination, each row has a
+// value per column.
+func (e *StatementExpectation) WillReturnRows(columns []string, rows ...[]any) *Mock {
+	mockRows := sqlmock.NewRows(columns)
+	for _, row := range rows {
+		values := make([]driver.Value, 0, len(row))
+		for _, value := range row {
+			values = append(values, value)
+		}
+		mockRows.AddRow(values...)
+	}
+	e.expectQuery().WillReturnRows(mockRows)
+
+	return e.mock
+}
+
+// WillReturnExecError expect a statement without destination to fail with
+// err, e.g. one of the typed driver errors.
+func (e *StatementExpectation) WillReturnExecError(err error) *Mock {
+	e.expectExec().WillReturnError(err)
+
+	return e.mock
+}
+
+// WillReturnQueryError expect a statement with a destination to fail with
+// err, e.g. one of the typed driver errors.
+func (e *StatementExpectation) WillReturnQueryError(err error) *Mock {
+	e.expectQuery().WillReturnError(err)
+
+	return e.mock
+}
+
+// WillFailPrepare expect the statement to fail to prepare with err.
+func (e *StatementExpectation) WillFailPrepare(err error) *Mock {
+	e.mock.Sqlmock.ExpectPrepare(e.sql).WillReturnError(err)
+
+	return e.mock
+}
+
+func (e *StatementExpectation) expectExec() *sqlmock.ExpectedExec {
+	var exec *sqlmock.ExpectedExec
+	if e.mock.bypassPrepare {
+		exec = e.mock.Sqlmock.ExpectExec(e.sql)
+	} else {
+		exec = e.mock.Sqlmock.ExpectPrepare(e.sql).WillBeClosed().ExpectExec()
+	}
+	if e.args != nil {
+		exec = exec.WithArgs(e.args...)
+	}
+
+	return exec
+}
+
+func (e *StatementExpectation) expectQuery() *sqlmock.ExpectedQuery {
+	var query *sqlmock.ExpectedQuery
+	if e.mock.bypassPrepare {
+		query = e.mock.Sqlmock.ExpectQuery(e.sql)
+	} else {
+		query = e.mock.Sqlmock.ExpectPrepare(e.sql).WillBeClosed().ExpectQuery()
+	}
+	if e.args != nil {
+		query = query.WithArgs(e.args...)
+	}
+
+	return query
+}
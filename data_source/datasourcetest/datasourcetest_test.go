@@ -0,0 +1,138 @@
+package datasourcetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"bitbucket.org/moladinTech/go-lib-common/data_source/datasourcetest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loan struct {
+	Id     int    `db:"id"`
+	Status string `db:"status"`
+}
+
+func Test_Mock(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			opts []datasourcetest.Option
+		}
+		want struct {
+			loans []loan
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Prepare",
+			want: want{loans: []loan{{Id: 1, Status: "new"}}},
+		},
+		{
+			name: "BypassPrepare",
+			args: args{opts: []datasourcetest.Option{datasourcetest.WithBypassPrepare()}},
+			want: want{loans: []loan{{Id: 1, Status: "new"}}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock := datasourcetest.New(t, testCase.args.opts...)
+			mock.ExpectStatement("SELECT id, status FROM loans WHERE status = $1").
+				WithArgs("new").
+				WillReturnRows([]string{"id", "status"}, []any{1, "new"}).
+				ExpectStatement("UPDATE loans SET status = $1 WHERE id = $2").
+				WithArgs("approved", 1).
+				WillReturnResult(0, 1)
+
+			var loans []loan
+			err := commonDataSource.Exec(context.Background(), mock.DB,
+				commonDataSource.NewStatement(&loans, "SELECT id, status FROM loans WHERE status = $1", "new"),
+				commonDataSource.NewStatement(nil, "UPDATE loans SET status = $1 WHERE id = $2", "approved", 1),
+			)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.loans, loans)
+		})
+	}
+}
+
+func Test_MockTx(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			execErr error
+		}
+		want struct {
+			err string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Commit",
+		},
+		{
+			name: "RollbackPqError",
+			args: args{execErr: datasourcetest.PqError(datasourcetest.PqUniqueViolation, "duplicate key value")},
+			want: want{err: "stmt[1]: pq: duplicate key value"},
+		},
+		{
+			name: "RollbackMysqlError",
+			args: args{execErr: datasourcetest.MysqlError(datasourcetest.MysqlDuplicateEntry, "Duplicate entry")},
+			want: want{err: "stmt[1]: Error 1062: Duplicate entry"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock := datasourcetest.New(t)
+			mock.ExpectBegin().
+				ExpectStatement("INSERT INTO loans (status) VALUES ($1)").
+				WithArgs("new").
+				WillReturnResult(1, 1)
+
+			insert := mock.ExpectStatement("INSERT INTO loan_histories (loan_id) VALUES ($1)").WithArgs(1)
+			if testCase.args.execErr != nil {
+				insert.WillReturnExecError(testCase.args.execErr).ExpectRollback()
+			} else {
+				insert.WillReturnResult(1, 1).ExpectCommit()
+			}
+
+			err := commonDataSource.ExecTx(context.Background(), mock.DB,
+				commonDataSource.NewStatement(nil, "INSERT INTO loans (status) VALUES ($1)", "new"),
+				commonDataSource.NewStatement(nil, "INSERT INTO loan_histories (loan_id) VALUES ($1)", 1),
+			)
+			if testCase.want.err == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.want.err)
+		})
+	}
+}
+
+func Test_MockBeginError(t *testing.T) {
+	t.Parallel()
+	mock := datasourcetest.New(t)
+	beginErr := errors.New("connection refused")
+	mock.ExpectBeginError(beginErr)
+
+	err := commonDataSource.ExecTx(context.Background(), mock.DB,
+		commonDataSource.NewStatement(nil, "DELETE FROM loans"),
+	)
+	assert.ErrorIs(t, err, beginErr)
+}
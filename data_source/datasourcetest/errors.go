@@ -0,0 +1,48 @@
+package datasourcetest
+
+import (
+	"database/sql/driver"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// SQLSTATE codes of the postgres errors mostly handled by services.
+const (
+	PqNotNullViolation     pq.ErrorCode = "23502"
+	PqForeignKeyViolation  pq.ErrorCode = "23503"
+	PqUniqueViolation      pq.ErrorCode = "23505"
+	PqSerializationFailure pq.ErrorCode = "40001"
+	PqDeadlockDetected     pq.ErrorCode = "40P01"
+	PqQueryCanceled        pq.ErrorCode = "57014"
+)
+
+// Error numbers of the mysql errors mostly handled by services.
+const (
+	MysqlLockWaitTimeout  uint16 = 1205
+	MysqlDeadlock         uint16 = 1213
+	MysqlDuplicateEntry   uint16 = 1062
+	MysqlNoReferencedRow  uint16 = 1452
+	MysqlQueryInterrupted uint16 = 1317
+)
+
+// ErrBadConn make database/sql retry the query on a new connection, or
+// fail a transaction.
+var ErrBadConn = driver.ErrBadConn
+
+// PqError return the error returned by lib/pq for code.
+func PqError(code pq.ErrorCode, message string) *pq.Error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// MysqlError return the error returned by go-sql-driver/mysql for number.
+func MysqlError(number uint16, message string) *mysql.MySQLError {
+	return &mysql.MySQLError{
+		Number:  number,
+		Message: message,
+	}
+}
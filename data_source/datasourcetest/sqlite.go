@@ -0,0 +1,45 @@
+//go:build sqlite
+
+package datasourcetest
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	SQLITE_DRIVER_NAME = "sqlite3"
+)
+
+// NewSQLite open an in-memory sqlite database, with foreign keys enforced,
+// for the repository tests needing real query execution. The schema
+// queries are executed in order and the database is gone when the test
+// finish. It's only built with -tags sqlite because of cgo.
+//
+// The database live in a single connection, so don't query outside of a
+// running transaction.
+func NewSQLite(t testing.TB, schema ...string) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Open(SQLITE_DRIVER_NAME, "file::memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("datasourcetest: failed to open sqlite: %s", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	for _, query := range schema {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("datasourcetest: failed to create the schema: %s", err)
+		}
+	}
+
+	return db
+}
@@ -0,0 +1,46 @@
+//go:build sqlite
+
+package datasourcetest_test
+
+import (
+	"context"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"bitbucket.org/moladinTech/go-lib-common/data_source/datasourcetest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewSQLite(t *testing.T) {
+	t.Parallel()
+	db := datasourcetest.NewSQLite(t,
+		"CREATE TABLE loans (id INTEGER PRIMARY KEY, status TEXT NOT NULL)",
+	)
+
+	err := commonDataSource.ExecTx(context.Background(), db,
+		commonDataSource.NewStatement(nil, "INSERT INTO loans (id, status) VALUES (?, ?)", 1, "new"),
+		commonDataSource.NewStatement(nil, "INSERT INTO loans (id, status) VALUES (?, ?)", 2, "approved"),
+	)
+	assert.Nil(t, err)
+
+	var loans []loan
+	err = commonDataSource.Exec(context.Background(), db,
+		commonDataSource.NewStatement(&loans, "SELECT id, status FROM loans ORDER BY id"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []loan{{Id: 1, Status: "new"}, {Id: 2, Status: "approved"}}, loans)
+
+	err = commonDataSource.ExecTx(context.Background(), db,
+		commonDataSource.NewStatement(nil, "INSERT INTO loans (id, status) VALUES (?, ?)", 3, "new"),
+		commonDataSource.NewStatement(nil, "INSERT INTO loans (id, status) VALUES (?, ?)", 1, "new"),
+	)
+	assert.NotNil(t, err)
+
+	var count int
+	err = commonDataSource.Exec(context.Background(), db,
+		commonDataSource.NewStatement(&count, "SELECT COUNT(*) FROM loans"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -1,8 +1,8 @@
 package data_source
 
 import (
-	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -27,15 +27,32 @@ type Config struct {
 	Password string `json:"password" yaml:"password"`
 	SSLMode  string `json:"sslMode" yaml:"sslMode"`
 
+	SSLRootCert      string            `json:"sslRootCert" yaml:"sslRootCert"`           // CA certificate file
+	SSLCert          string            `json:"sslCert" yaml:"sslCert"`                   // client certificate file
+	SSLKey           string            `json:"sslKey" yaml:"sslKey"`                     // client key file
+	Timezone         string            `json:"timezone" yaml:"timezone"`                 // e.g. Asia/Jakarta
+	ConnectTimeout   int               `json:"connectTimeout" yaml:"connectTimeout"`     // Seconds
+	StatementTimeout int               `json:"statementTimeout" yaml:"statementTimeout"` // Milliseconds
+	SearchPath       string            `json:"searchPath" yaml:"searchPath"`             // postgres only
+	ApplicationName  string            `json:"applicationName" yaml:"applicationName"`   // postgres only
+	Params           map[string]string `json:"params" yaml:"params"`                     // extra driver params
+
 	MaxOpenConnections    int `json:"maxOpenConnections" yaml:"maxOpenConnections"`
 	MaxLifeTimeConnection int `json:"maxLifeTimeConnection" yaml:"maxLifeTimeConnection"` // Seconds
 	MaxIdleConnections    int `json:"maxIdleConnections" yaml:"maxIdleConnections"`
 	MaxIdleTimeConnection int `json:"maxIdleTimeConnection" yaml:"maxIdleTimeConnection"` // Seconds
+
+	StatementCacheSize int  `json:"statementCacheSize" yaml:"statementCacheSize"` // 0 disable the statement cache
+	BypassPrepare      bool `json:"bypassPrepare" yaml:"bypassPrepare"`           // true for PgBouncer transaction pooling
 }
 
-// NewDB create new DB connection.
+// NewDB create new DB connection. Close it with CloseDB, the statement
+// cache and the prepare settings of the config are kept otherwise.
 func NewDB(config *Config) (*sqlx.DB, error) {
-	dsn := GetDsn(config)
+	dsn, err := BuildDsn(config)
+	if err != nil {
+		return nil, err
+	}
 
 	conn, err := sqlx.Open(config.Driver, dsn)
 	if err != nil {
@@ -51,22 +68,25 @@ func NewDB(config *Config) (*sqlx.DB, error) {
 		return nil, er
 	}
 
+	if config.StatementCacheSize > 0 {
+		EnableStatementCache(conn, config.StatementCacheSize)
+	}
+	if config.BypassPrepare {
+		BypassPrepare(conn, true)
+	}
+
 	return conn, nil
 }
 
+// GetDsn return the data source name of config, or empty string when the
+// driver isn't supported. Use BuildDsn to get the error.
 func GetDsn(config *Config) string {
-	if config.Driver == "mysql" {
-		return fmt.Sprintf(
-			"%s:%s@(%s:%d)/%s?parseTime=true",
-			config.User, config.Password, config.Host, config.Port, config.DBName,
-		)
-	} else if config.Driver == "postgres" {
-		return fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-		)
+	dsn, err := BuildDsn(config)
+	if err != nil {
+		return ""
 	}
-	return ""
+
+	return dsn
 }
 
 func GetDbColumnsAndValue(data any, excluded ...string) map[string]interface{} {
@@ -76,7 +96,7 @@ func GetDbColumnsAndValue(data any, excluded ...string) map[string]interface{} {
 	res := map[string]interface{}{}
 	for i := 0; i < v.NumField(); i++ {
 		variableValue := v.Field(i).Interface()
-		columName := typeOfS.Field(i).Tag.Get("db")
+		columName, _, _ := strings.Cut(typeOfS.Field(i).Tag.Get("db"), ",")
 		if !slices.Contains(excluded, columName) {
 			res[columName] = variableValue
 		}
@@ -90,7 +110,7 @@ func GetDbColumns(data any, excluded ...string) []string {
 
 	res := make([]string, 0)
 	for i := 0; i < v.NumField(); i++ {
-		columName := typeOfS.Field(i).Tag.Get("db")
+		columName, _, _ := strings.Cut(typeOfS.Field(i).Tag.Get("db"), ",")
 		if !slices.Contains(excluded, columName) {
 			res = append(res, columName)
 		}
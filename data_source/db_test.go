@@ -38,7 +38,7 @@ func Test_GetDSN(t *testing.T) {
 				},
 			},
 			want: want{
-				dsn: "user1:password1@(localhost:1234)/dummyDB?parseTime=true",
+				dsn: "user1:password1@tcp(localhost:1234)/dummyDB?parseTime=true",
 			},
 		},
 		{
@@ -71,6 +71,127 @@ func Test_GetDSN(t *testing.T) {
 	}
 }
 
+func Test_BuildDsn(t *testing.T) {
+	t.Parallel()
+
+	type (
+		args struct {
+			config *commonDataSource.Config
+		}
+		want struct {
+			dsn string
+			err error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Mysql with ssl, timezone, timeouts and params",
+			args: args{
+				config: &commonDataSource.Config{
+					Driver:           commonDataSource.DriverMysql,
+					Host:             "localhost",
+					Port:             3306,
+					DBName:           "dummyDB",
+					User:             "user1",
+					Password:         "p@ss:w/rd",
+					SSLMode:          "require",
+					Timezone:         "Asia/Jakarta",
+					ConnectTimeout:   5,
+					StatementTimeout: 3000,
+					Params:           map[string]string{"charset": "utf8mb4"},
+				},
+			},
+			want: want{
+				dsn: "user1:p@ss:w/rd@tcp(localhost:3306)/dummyDB?loc=Asia%2FJakarta&parseTime=true" +
+					"&timeout=5s&tls=skip-verify&charset=utf8mb4&max_execution_time=3000",
+			},
+		},
+		{
+			name: "Mysql with session time zone through params",
+			args: args{
+				config: &commonDataSource.Config{
+					Driver:   commonDataSource.DriverMysql,
+					Host:     "localhost",
+					Port:     3306,
+					DBName:   "dummyDB",
+					User:     "user1",
+					Password: "password1",
+					Timezone: "Asia/Jakarta",
+					Params:   map[string]string{"time_zone": "'Asia/Jakarta'"},
+				},
+			},
+			want: want{
+				dsn: "user1:password1@tcp(localhost:3306)/dummyDB?loc=Asia%2FJakarta&parseTime=true" +
+					"&time_zone=%27Asia%2FJakarta%27",
+			},
+		},
+		{
+			name: "Postgres with escaped password, search path and timeouts",
+			args: args{
+				config: &commonDataSource.Config{
+					Driver:           commonDataSource.DriverPostgres,
+					Host:             "localhost",
+					Port:             5432,
+					DBName:           "dummyDB",
+					User:             "user1",
+					Password:         `it's a\secret`,
+					SSLMode:          "verify-full",
+					SSLRootCert:      "/certs/ca.pem",
+					ConnectTimeout:   5,
+					StatementTimeout: 3000,
+					Timezone:         "Asia/Jakarta",
+					SearchPath:       "loan,public",
+					ApplicationName:  "loan-service",
+					Params:           map[string]string{"lock_timeout": "1000"},
+				},
+			},
+			want: want{
+				dsn: `host=localhost port=5432 user=user1 password='it\'s a\\secret' dbname=dummyDB ` +
+					`sslmode=verify-full sslrootcert=/certs/ca.pem connect_timeout=5 statement_timeout=3000 ` +
+					`timezone=Asia/Jakarta search_path=loan,public application_name=loan-service lock_timeout=1000`,
+			},
+		},
+		{
+			name: "Pgx",
+			args: args{
+				config: &commonDataSource.Config{
+					Driver:   commonDataSource.DriverPgx,
+					Host:     "localhost",
+					Port:     5432,
+					DBName:   "dummyDB",
+					User:     "user1",
+					Password: "password1",
+				},
+			},
+			want: want{
+				dsn: "host=localhost port=5432 user=user1 password=password1 dbname=dummyDB",
+			},
+		},
+		{
+			name: "Unsupported driver",
+			args: args{
+				config: &commonDataSource.Config{Driver: "oracle"},
+			},
+			want: want{err: commonDataSource.ErrUnsupportedDriver},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dsn, err := commonDataSource.BuildDsn(testCase.config)
+
+			assert.ErrorIs(t, err, testCase.want.err)
+			assert.Equal(t, testCase.want.dsn, dsn)
+		})
+	}
+}
+
 func Test_GetDbColumnsAndValue(t *testing.T) {
 	t.Parallel()
 	var dummyTableAndValue = struct {
@@ -0,0 +1,212 @@
+package data_source
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverPgx      = "pgx"
+)
+
+var ErrUnsupportedDriver = errors.New("unsupported database driver")
+
+// BuildDsn build the data source name of config for mysql, postgres (lib/pq)
+// and pgx. Unknown drivers return ErrUnsupportedDriver.
+func BuildDsn(config *Config) (string, error) {
+	switch config.Driver {
+	case DriverMysql:
+		return buildMysqlDsn(config)
+	case DriverPostgres, DriverPgx:
+		return buildPostgresDsn(config), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedDriver, config.Driver)
+	}
+}
+
+// buildPostgresDsn build the key=value format understood by lib/pq and pgx,
+// unknown keys are sent as run-time parameters.
+func buildPostgresDsn(config *Config) string {
+	params := []struct {
+		key   string
+		value string
+	}{
+		{"host", config.Host},
+		{"port", positiveItoa(config.Port)},
+		{"user", config.User},
+		{"password", config.Password},
+		{"dbname", config.DBName},
+		{"sslmode", config.SSLMode},
+		{"sslrootcert", config.SSLRootCert},
+		{"sslcert", config.SSLCert},
+		{"sslkey", config.SSLKey},
+		{"connect_timeout", positiveItoa(config.ConnectTimeout)},
+		{"statement_timeout", positiveItoa(config.StatementTimeout)},
+		{"timezone", config.Timezone},
+		{"search_path", config.SearchPath},
+		{"application_name", config.ApplicationName},
+	}
+	for _, key := range sortedKeys(config.Params) {
+		params = append(params, struct {
+			key   string
+			value string
+		}{key, config.Params[key]})
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.value == "" {
+			continue
+		}
+		pairs = append(pairs, param.key+"="+quotePostgresValue(param.value))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// quotePostgresValue quote value when it contains spaces, quotes or
+// backslashes, as required by the key=value format.
+func quotePostgresValue(value string) string {
+	if !strings.ContainsAny(value, " '\\\t\n") {
+		return value
+	}
+
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// buildMysqlDsn build the dsn with mysql.Config so the driver handles the
+// escaping. Timezone only set loc, the session time_zone requires the time
+// zone tables on the server and is set through Params, e.g.
+// Params{"time_zone": "'Asia/Jakarta'"}.
+func buildMysqlDsn(config *Config) (string, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = config.User
+	mysqlConfig.Passwd = config.Password
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	mysqlConfig.DBName = config.DBName
+	mysqlConfig.ParseTime = true
+
+	tlsParam, err := mysqlTLS(config)
+	if err != nil {
+		return "", err
+	}
+	mysqlConfig.TLSConfig = tlsParam
+
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return "", err
+		}
+		mysqlConfig.Loc = loc
+	}
+	if config.ConnectTimeout > 0 {
+		mysqlConfig.Timeout = time.Duration(config.ConnectTimeout) * time.Second
+	}
+
+	params := make(map[string]string, len(config.Params)+1)
+	if config.StatementTimeout > 0 {
+		// only applied to SELECT by mysql
+		params["max_execution_time"] = strconv.Itoa(config.StatementTimeout)
+	}
+	for key, value := range config.Params {
+		params[key] = value
+	}
+	if len(params) > 0 {
+		mysqlConfig.Params = params
+	}
+
+	return mysqlConfig.FormatDSN(), nil
+}
+
+// mysqlTLS map the postgres style SSLMode to the tls param of mysql, the
+// certificates are registered as a custom tls config named after the host.
+func mysqlTLS(config *Config) (string, error) {
+	var insecureSkipVerify bool
+	switch config.SSLMode {
+	case "":
+		return "", nil
+	case "disable", "disabled", "false":
+		return "false", nil
+	case "prefer", "preferred":
+		return "preferred", nil
+	case "require":
+		insecureSkipVerify = true
+	case "verify-ca", "verify-full", "true":
+	default:
+		// name of a tls config registered with mysql.RegisterTLSConfig
+		return config.SSLMode, nil
+	}
+
+	if config.SSLRootCert == "" && config.SSLCert == "" {
+		if insecureSkipVerify {
+			return "skip-verify", nil
+		}
+		return "true", nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         config.Host,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if config.SSLRootCert != "" {
+		rootCert, err := os.ReadFile(config.SSLRootCert)
+		if err != nil {
+			return "", err
+		}
+
+		rootCertPool := x509.NewCertPool()
+		if !rootCertPool.AppendCertsFromPEM(rootCert) {
+			return "", fmt.Errorf("failed to append the root certificate %s", config.SSLRootCert)
+		}
+		tlsConfig.RootCAs = rootCertPool
+	}
+
+	if config.SSLCert != "" {
+		cert, err := tls.LoadX509KeyPair(config.SSLCert, config.SSLKey)
+		if err != nil {
+			return "", err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	name := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+func positiveItoa(value int) string {
+	if value <= 0 {
+		return ""
+	}
+
+	return strconv.Itoa(value)
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,10 +1,49 @@
 package data_source
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
+// ParallelError is returned by ExecParallel, Errors is keyed by the index
+// of the failed statements.
+type ParallelError struct {
+	Errors map[int]error
+}
+
+func (e *ParallelError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, index := range e.indexes() {
+		messages = append(messages, fmt.Sprintf("stmt[%d]: %s", index, e.Errors[index].Error()))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap allow errors.Is and errors.As to match any of the statement errors.
+func (e *ParallelError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, index := range e.indexes() {
+		errs = append(errs, e.Errors[index])
+	}
+
+	return errs
+}
+
+func (e *ParallelError) indexes() []int {
+	indexes := make([]int, 0, len(e.Errors))
+	for index := range e.Errors {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	return indexes
+}
+
 func IsErrDuplicateKey(err error) bool {
 	var psqlErr *pq.Error // ERROR postgres package has not type or method
 	if errors.As(err, &psqlErr) && psqlErr.Code == "1062" {
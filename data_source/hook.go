@@ -0,0 +1,234 @@
+package data_source
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	commonSentry "bitbucket.org/moladinTech/go-lib-common/sentry"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jmoiron/sqlx"
+)
+
+type QueryOperation string
+
+const (
+	QueryExec   QueryOperation = "exec"
+	QuerySelect QueryOperation = "select"
+	QueryGet    QueryOperation = "get"
+	QueryEach   QueryOperation = "each"
+	QueryTx     QueryOperation = "tx"
+
+	// RedactedArg replace the args marked by Statement.Redact in QueryEvent.
+	RedactedArg = "[REDACTED]"
+
+	SentryQuerySpan       = "db.query"
+	SentryTransactionSpan = "db.transaction"
+)
+
+// QueryEvent describe a single statement execution, or a whole
+// transaction when Operation is QueryTx. Duration, RowsAffected and Err
+// are only filled in AfterQuery.
+type QueryEvent struct {
+	Operation    QueryOperation
+	Query        string
+	Args         []any
+	InTx         bool
+	StartedAt    time.Time
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+// QueryHook is invoked around every statement run by Exec, ExecTx and
+// TransactionRunner.WithTx. The context returned by BeforeQuery is used
+// to run the query and is passed to AfterQuery.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// AddQueryHook register hooks for every statement executed against db,
+// close db with CloseDB to remove them.
+func AddQueryHook(db *sqlx.DB, hooks ...QueryHook) {
+	updateDBOption(db, func(option *dbOption) {
+		option.hooks = append(option.hooks, hooks...)
+	})
+}
+
+// RemoveQueryHooks remove all hooks registered for db.
+func RemoveQueryHooks(db *sqlx.DB) {
+	updateDBOption(db, func(option *dbOption) {
+		option.hooks = nil
+	})
+}
+
+func getQueryHooks(db *sqlx.DB) []QueryHook {
+	return getDBOption(db).hooks
+}
+
+// runQueryHooks run fn between the hooks, fn return the rows affected.
+// Hooks are called in registration order before and reverse order after.
+func runQueryHooks(ctx context.Context, hooks []QueryHook, event *QueryEvent, fn func(ctx context.Context) (int64, error)) error {
+	if len(hooks) == 0 {
+		event.RowsAffected, event.Err = fn(ctx)
+		return event.Err
+	}
+
+	event.StartedAt = time.Now()
+	for _, hook := range hooks {
+		ctx = hook.BeforeQuery(ctx, event)
+	}
+
+	rowsAffected, err := fn(ctx)
+	event.Duration = time.Since(event.StartedAt)
+	event.RowsAffected = rowsAffected
+	event.Err = err
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, event)
+	}
+
+	return err
+}
+
+type querySpanKey struct{}
+
+type sentryQueryHook struct {
+	sentry commonSentry.ISentry
+}
+
+// NewSentryQueryHook create a hook starting a db.query span for every
+// statement and a db.transaction span for every WithTx.
+func NewSentryQueryHook(sentry commonSentry.ISentry) QueryHook {
+	return &sentryQueryHook{sentry: sentry}
+}
+
+func (h *sentryQueryHook) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	spanName := SentryQuerySpan
+	if event.Operation == QueryTx {
+		spanName = SentryTransactionSpan
+	}
+
+	span := h.sentry.StartSpan(ctx, spanName)
+	if span == nil {
+		return ctx
+	}
+	span.Description = event.Query
+	h.sentry.SetTag(span, "db.operation", string(event.Operation))
+
+	return context.WithValue(span.Context(), querySpanKey{}, span)
+}
+
+func (h *sentryQueryHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	span, ok := ctx.Value(querySpanKey{}).(*sentry.Span)
+	if !ok {
+		return
+	}
+
+	span.Status = sentry.SpanStatusOK
+	if event.Err != nil {
+		span.Status = sentry.SpanStatusInternalError
+	}
+	if span.Data == nil {
+		span.Data = make(map[string]interface{})
+	}
+	span.Data["db.rows_affected"] = event.RowsAffected
+	h.sentry.Finish(span)
+}
+
+type slowQueryHook struct {
+	threshold time.Duration
+}
+
+// NewSlowQueryHook create a hook logging a warning for every statement
+// or transaction taking threshold or longer.
+func NewSlowQueryHook(threshold time.Duration) QueryHook {
+	return &slowQueryHook{threshold: threshold}
+}
+
+func (h *slowQueryHook) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *slowQueryHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	if event.Duration < h.threshold {
+		return
+	}
+
+	logger.Warn(ctx, "slow query",
+		logger.Tag{Key: "logCtx", Value: "common.data_source.SlowQueryHook"},
+		logger.Tag{Key: "operation", Value: string(event.Operation)},
+		logger.Tag{Key: "query", Value: event.Query},
+		logger.Tag{Key: "args", Value: fmt.Sprintf("%v", event.Args)},
+		logger.Tag{Key: "duration", Value: event.Duration.String()},
+		logger.Tag{Key: "rowsAffected", Value: event.RowsAffected},
+	)
+}
+
+// QueryStats is the aggregated metrics of a query.
+type QueryStats struct {
+	Operation     QueryOperation
+	Count         uint64
+	ErrorCount    uint64
+	RowsAffected  int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// MetricsQueryHook aggregate QueryStats per query text, read them with
+// Stats to push them to your metrics backend.
+type MetricsQueryHook struct {
+	mu    sync.Mutex
+	stats map[string]QueryStats
+}
+
+func NewMetricsQueryHook() *MetricsQueryHook {
+	return &MetricsQueryHook{stats: make(map[string]QueryStats)}
+}
+
+func (h *MetricsQueryHook) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *MetricsQueryHook) AfterQuery(_ context.Context, event *QueryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := h.stats[event.Query]
+	stats.Operation = event.Operation
+	stats.Count++
+	if event.Err != nil {
+		stats.ErrorCount++
+	}
+	stats.RowsAffected += event.RowsAffected
+	stats.TotalDuration += event.Duration
+	if event.Duration > stats.MaxDuration {
+		stats.MaxDuration = event.Duration
+	}
+	h.stats[event.Query] = stats
+}
+
+// Stats return a copy of the aggregated metrics keyed by query text.
+func (h *MetricsQueryHook) Stats() map[string]QueryStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make(map[string]QueryStats, len(h.stats))
+	for query, stat := range h.stats {
+		stats[query] = stat
+	}
+
+	return stats
+}
+
+// Reset clear the aggregated metrics.
+func (h *MetricsQueryHook) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stats = make(map[string]QueryStats)
+}
@@ -0,0 +1,116 @@
+package data_source_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"bitbucket.org/moladinTech/go-lib-common/sentry/mocks"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/getsentry/sentry-go"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordQueryHook struct {
+	before []commonDataSource.QueryEvent
+	after  []commonDataSource.QueryEvent
+}
+
+func (h *recordQueryHook) BeforeQuery(ctx context.Context, event *commonDataSource.QueryEvent) context.Context {
+	h.before = append(h.before, *event)
+	return ctx
+}
+
+func (h *recordQueryHook) AfterQuery(_ context.Context, event *commonDataSource.QueryEvent) {
+	h.after = append(h.after, *event)
+}
+
+func Test_QueryHook(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	hook := &recordQueryHook{}
+	metrics := commonDataSource.NewMetricsQueryHook()
+	commonDataSource.AddQueryHook(dbmock, hook, metrics)
+	defer commonDataSource.RemoveQueryHooks(dbmock)
+
+	selectQuery := "SELECT id FROM users WHERE email = ?"
+	updateQuery := "UPDATE users SET password = ? WHERE id = ?"
+	queryMock.ExpectPrepare(selectQuery).ExpectQuery().WithArgs("john@mail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	queryMock.ExpectPrepare(updateQuery).ExpectExec().WithArgs("secret", 1).
+		WillReturnError(errors.New("connection reset"))
+
+	var ids []int
+	err := commonDataSource.Exec(context.Background(), dbmock,
+		commonDataSource.NewStatement(&ids, selectQuery, "john@mail.com"),
+		commonDataSource.NewStatement(nil, updateQuery, "secret", 1).Redact(0),
+	)
+
+	assert.NotNil(t, err)
+	assert.Len(t, hook.before, 2)
+	assert.Len(t, hook.after, 2)
+
+	assert.Equal(t, commonDataSource.QuerySelect, hook.after[0].Operation)
+	assert.Equal(t, selectQuery, hook.after[0].Query)
+	assert.Equal(t, int64(2), hook.after[0].RowsAffected)
+	assert.Nil(t, hook.after[0].Err)
+
+	assert.Equal(t, commonDataSource.QueryExec, hook.after[1].Operation)
+	assert.Equal(t, []any{commonDataSource.RedactedArg, 1}, hook.after[1].Args)
+	assert.NotNil(t, hook.after[1].Err)
+
+	stats := metrics.Stats()
+	assert.Equal(t, uint64(1), stats[selectQuery].Count)
+	assert.Equal(t, int64(2), stats[selectQuery].RowsAffected)
+	assert.Equal(t, uint64(1), stats[updateQuery].ErrorCount)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_QueryHookWithTx(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	hook := &recordQueryHook{}
+	commonDataSource.AddQueryHook(dbmock, hook)
+	defer commonDataSource.RemoveQueryHooks(dbmock)
+
+	queryMock.ExpectBegin()
+	queryMock.ExpectCommit()
+
+	err := commonDataSource.NewTransactionRunner(dbmock).WithTx(context.Background(), func(tx *sqlx.Tx) error {
+		return nil
+	}, &sql.TxOptions{})
+
+	assert.Nil(t, err)
+	assert.Len(t, hook.after, 1)
+	assert.Equal(t, commonDataSource.QueryTx, hook.after[0].Operation)
+	assert.True(t, hook.after[0].InTx)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_SentryQueryHook(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	span := sentry.StartSpan(context.Background(), commonDataSource.SentryQuerySpan)
+	sentryMock := mocks.NewISentry(t)
+	sentryMock.On("StartSpan", mock.Anything, commonDataSource.SentryQuerySpan).Return(span).Once()
+	sentryMock.On("SetTag", span, "db.operation", string(commonDataSource.QueryExec)).Once()
+	sentryMock.On("Finish", span).Once()
+	commonDataSource.AddQueryHook(dbmock, commonDataSource.NewSentryQueryHook(sentryMock))
+	defer commonDataSource.RemoveQueryHooks(dbmock)
+
+	query := "DELETE FROM users WHERE id = ?"
+	queryMock.ExpectPrepare(query).ExpectExec().WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := commonDataSource.Exec(context.Background(), dbmock, commonDataSource.NewStatement(nil, query, 1))
+
+	assert.Nil(t, err)
+	assert.Equal(t, query, span.Description)
+	assert.Equal(t, sentry.SpanStatusOK, span.Status)
+	assert.Equal(t, int64(1), span.Data["db.rows_affected"])
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
@@ -0,0 +1,373 @@
+package data_source
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrMigrationSourceRequired is returned when the MigrationSource has
+// neither FS nor Path.
+var ErrMigrationSourceRequired = stderrors.New("migration source FS or Path is required")
+
+type MigrationAction string
+
+const (
+	MigrationUp    MigrationAction = "up"
+	MigrationDown  MigrationAction = "down"
+	MigrationSteps MigrationAction = "steps"
+	MigrationGoto  MigrationAction = "goto"
+	MigrationForce MigrationAction = "force"
+)
+
+// MigrationSource is the location of the migration files, named
+// {version}_{title}.up.sql and {version}_{title}.down.sql.
+// FS is usually an embed.FS, when it is nil Path is read from the disk.
+type MigrationSource struct {
+	FS   fs.FS
+	Path string
+}
+
+// MigrationDirection tell Migrate what to do, use MigrateUp, MigrateDown,
+// MigrateSteps, MigrateGoto or MigrateForce to create it.
+type MigrationDirection struct {
+	Action  MigrationAction
+	Steps   int
+	Version uint
+}
+
+func MigrateUp() MigrationDirection {
+	return MigrationDirection{Action: MigrationUp}
+}
+
+func MigrateDown() MigrationDirection {
+	return MigrationDirection{Action: MigrationDown}
+}
+
+// MigrateSteps migrate n versions up, or down when n is negative.
+func MigrateSteps(n int) MigrationDirection {
+	return MigrationDirection{Action: MigrationSteps, Steps: n}
+}
+
+// MigrateGoto migrate up or down to the version.
+func MigrateGoto(version uint) MigrationDirection {
+	return MigrationDirection{Action: MigrationGoto, Version: version}
+}
+
+// MigrateForce set the version without running any migration, used to
+// clean the dirty state after a failed migration is fixed by hand.
+func MigrateForce(version uint) MigrationDirection {
+	return MigrationDirection{Action: MigrationForce, Version: version}
+}
+
+type migrationOption struct {
+	lockTimeout     time.Duration
+	migrationsTable string
+}
+
+type MigrationOption func(*migrationOption)
+
+// WithLockTimeout set how long a pod waits for the advisory lock held by
+// another pod running the migration, default 15 seconds.
+func WithLockTimeout(lockTimeout time.Duration) MigrationOption {
+	return func(o *migrationOption) {
+		o.lockTimeout = lockTimeout
+	}
+}
+
+// WithMigrationsTable set the table storing the migration version,
+// default schema_migrations.
+func WithMigrationsTable(migrationsTable string) MigrationOption {
+	return func(o *migrationOption) {
+		o.migrationsTable = migrationsTable
+	}
+}
+
+type migrationLogger struct {
+	ctx context.Context
+}
+
+func (l migrationLogger) Printf(format string, v ...interface{}) {
+	logger.Info(l.ctx, fmt.Sprintf(format, v...), logger.Tag{Key: "logCtx", Value: "common.data_source.Migrate"})
+}
+
+func (l migrationLogger) Verbose() bool {
+	return false
+}
+
+// Migrate run the migrations of source against db. Migration is guarded by
+// the database advisory lock (pg_advisory_lock on postgres, GET_LOCK on
+// mysql) so only one pod migrates at a time. Having nothing to migrate is
+// not an error. Errors are wrapped with errors.ErrMigrate.
+func Migrate(ctx context.Context, db *sqlx.DB, src MigrationSource, direction MigrationDirection, opts ...MigrationOption) error {
+	m, err := newMigrate(ctx, db, src, opts...)
+	if err != nil {
+		return wrapErrMigrate(err)
+	}
+	defer m.Close()
+
+	// stop after the running migration when ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.GracefulStop <- true
+		case <-done:
+		}
+	}()
+
+	switch direction.Action {
+	case MigrationUp:
+		err = m.Up()
+	case MigrationDown:
+		err = m.Down()
+	case MigrationSteps:
+		err = m.Steps(direction.Steps)
+	case MigrationGoto:
+		err = m.Migrate(direction.Version)
+	case MigrationForce:
+		err = m.Force(int(direction.Version))
+	default:
+		err = fmt.Errorf("unknown migration action %q", direction.Action)
+	}
+
+	if err != nil && !stderrors.Is(err, migrate.ErrNoChange) {
+		return wrapErrMigrate(err)
+	}
+
+	return nil
+}
+
+// MigrateDryRun return the versions Migrate would apply for direction, in
+// the order they would be applied, without running them. The version is
+// only read, the migrations table isn't created nor locked.
+func MigrateDryRun(ctx context.Context, db *sqlx.DB, src MigrationSource, direction MigrationDirection, opts ...MigrationOption) ([]uint, error) {
+	option := newMigrationOption(opts...)
+
+	currentVersion, dirty, err := readMigrationVersion(ctx, db, option.migrationsTable)
+	if err != nil {
+		return nil, wrapErrMigrate(err)
+	}
+
+	if dirty {
+		return nil, wrapErrMigrate(migrate.ErrDirty{Version: currentVersion})
+	}
+
+	sourceDriver, err := openMigrationSource(src)
+	if err != nil {
+		return nil, wrapErrMigrate(err)
+	}
+	defer sourceDriver.Close()
+
+	versions, err := migrationVersions(sourceDriver)
+	if err != nil {
+		return nil, wrapErrMigrate(err)
+	}
+
+	return pendingVersions(versions, currentVersion, direction), nil
+}
+
+func newMigrationOption(opts ...MigrationOption) *migrationOption {
+	option := &migrationOption{lockTimeout: migrate.DefaultLockTimeout}
+	for _, opt := range opts {
+		opt(option)
+	}
+
+	return option
+}
+
+// readMigrationVersion read the version of the migrations table like
+// golang-migrate does, database.NilVersion when the table doesn't exist
+// or is empty.
+func readMigrationVersion(ctx context.Context, db *sqlx.DB, migrationsTable string) (int, bool, error) {
+	if migrationsTable == "" {
+		migrationsTable = postgres.DefaultMigrationsTable
+	}
+
+	var existsQuery, versionQuery string
+	switch db.DriverName() {
+	case DriverPostgres, DriverPgx:
+		existsQuery = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+		versionQuery = "SELECT version, dirty FROM " + pq.QuoteIdentifier(migrationsTable) + " LIMIT 1"
+	case DriverMysql:
+		existsQuery = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+		versionQuery = "SELECT version, dirty FROM `" + strings.ReplaceAll(migrationsTable, "`", "``") + "` LIMIT 1"
+	default:
+		return 0, false, fmt.Errorf("migration is not supported for driver %s", db.DriverName())
+	}
+
+	var count int
+	if err := db.QueryRowxContext(ctx, db.Rebind(existsQuery), migrationsTable).Scan(&count); err != nil {
+		return 0, false, err
+	}
+	if count == 0 {
+		return database.NilVersion, false, nil
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowxContext(ctx, versionQuery).Scan(&version, &dirty)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return database.NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return int(version), dirty, nil
+}
+
+func newMigrate(ctx context.Context, db *sqlx.DB, src MigrationSource, opts ...MigrationOption) (*migrate.Migrate, error) {
+	option := newMigrationOption(opts...)
+
+	// use a single connection so closing the migration doesn't close db
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseDriver database.Driver
+	switch db.DriverName() {
+	case DriverPostgres, DriverPgx:
+		databaseDriver, err = postgres.WithConnection(ctx, conn, &postgres.Config{MigrationsTable: option.migrationsTable})
+	case DriverMysql:
+		databaseDriver, err = mysql.WithConnection(ctx, conn, &mysql.Config{MigrationsTable: option.migrationsTable})
+	default:
+		err = fmt.Errorf("migration is not supported for driver %s", db.DriverName())
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	sourceDriver, err := openMigrationSource(src)
+	if err != nil {
+		_ = databaseDriver.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", sourceDriver, db.DriverName(), databaseDriver)
+	if err != nil {
+		_ = sourceDriver.Close()
+		_ = databaseDriver.Close()
+		return nil, err
+	}
+	m.LockTimeout = option.lockTimeout
+	m.Log = migrationLogger{ctx: ctx}
+
+	return m, nil
+}
+
+func openMigrationSource(src MigrationSource) (source.Driver, error) {
+	if src.FS == nil {
+		if src.Path == "" {
+			return nil, ErrMigrationSourceRequired
+		}
+		return iofs.New(os.DirFS(src.Path), ".")
+	}
+
+	path := src.Path
+	if path == "" {
+		path = "."
+	}
+	return iofs.New(src.FS, path)
+}
+
+// migrationVersions list all versions of the source in ascending order.
+func migrationVersions(sourceDriver source.Driver) ([]uint, error) {
+	versions := make([]uint, 0)
+
+	version, err := sourceDriver.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = sourceDriver.Next(version)
+	}
+
+	if !stderrors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// pendingVersions return the versions applied when migrating from
+// currentVersion in direction, versions must be in ascending order.
+func pendingVersions(versions []uint, currentVersion int, direction MigrationDirection) []uint {
+	applied := make([]uint, 0)
+	above := make([]uint, 0)
+	for _, version := range versions {
+		if int(version) <= currentVersion {
+			applied = append(applied, version)
+		} else {
+			above = append(above, version)
+		}
+	}
+
+	// down migrations are applied from the latest version
+	for i, j := 0, len(applied)-1; i < j; i, j = i+1, j-1 {
+		applied[i], applied[j] = applied[j], applied[i]
+	}
+
+	switch direction.Action {
+	case MigrationUp:
+		return above
+	case MigrationDown:
+		return applied
+	case MigrationSteps:
+		if direction.Steps >= 0 {
+			return above[:minInt(direction.Steps, len(above))]
+		}
+		return applied[:minInt(-direction.Steps, len(applied))]
+	case MigrationGoto:
+		pending := make([]uint, 0)
+		if int(direction.Version) > currentVersion {
+			for _, version := range above {
+				if version <= direction.Version {
+					pending = append(pending, version)
+				}
+			}
+			return pending
+		}
+		for _, version := range applied {
+			if version > direction.Version {
+				pending = append(pending, version)
+			}
+		}
+		return pending
+	default:
+		return []uint{}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// wrapErrMigrate keep err in the chain, so migrate.ErrDirty and the driver
+// errors are still matched by errors.Is and errors.As.
+func wrapErrMigrate(err error) error {
+	return fmt.Errorf("%w: %w", commonErrors.ErrMigrate, err)
+}
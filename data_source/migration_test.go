@@ -0,0 +1,180 @@
+package data_source_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	postgresMigrationsExistsQuery = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	postgresMigrationVersionQuery = `SELECT version, dirty FROM "schema_migrations" LIMIT 1`
+)
+
+func migrationSource() commonDataSource.MigrationSource {
+	files := fstest.MapFS{}
+	for _, name := range []string{"1_create_users", "2_add_name", "3_add_email", "4_add_phone", "5_add_index"} {
+		files["migrations/"+name+".up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		files["migrations/"+name+".down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	}
+
+	return commonDataSource.MigrationSource{FS: files, Path: "migrations"}
+}
+
+func Test_MigrateDryRun(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			// currentVersion is 0 when the migrations table doesn't exist
+			currentVersion int
+			dirty          bool
+			direction      commonDataSource.MigrationDirection
+		}
+		want struct {
+			versions []uint
+			err      error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Up without migrations table",
+			args: args{direction: commonDataSource.MigrateUp()},
+			want: want{versions: []uint{1, 2, 3, 4, 5}},
+		},
+		{
+			name: "Up from version 3",
+			args: args{currentVersion: 3, direction: commonDataSource.MigrateUp()},
+			want: want{versions: []uint{4, 5}},
+		},
+		{
+			name: "Down from version 3",
+			args: args{currentVersion: 3, direction: commonDataSource.MigrateDown()},
+			want: want{versions: []uint{3, 2, 1}},
+		},
+		{
+			name: "Steps up more than available",
+			args: args{currentVersion: 3, direction: commonDataSource.MigrateSteps(5)},
+			want: want{versions: []uint{4, 5}},
+		},
+		{
+			name: "Steps down",
+			args: args{currentVersion: 3, direction: commonDataSource.MigrateSteps(-2)},
+			want: want{versions: []uint{3, 2}},
+		},
+		{
+			name: "Goto upper version",
+			args: args{currentVersion: 1, direction: commonDataSource.MigrateGoto(4)},
+			want: want{versions: []uint{2, 3, 4}},
+		},
+		{
+			name: "Goto lower version",
+			args: args{currentVersion: 5, direction: commonDataSource.MigrateGoto(2)},
+			want: want{versions: []uint{5, 4, 3}},
+		},
+		{
+			name: "Force",
+			args: args{currentVersion: 2, direction: commonDataSource.MigrateForce(4)},
+			want: want{versions: []uint{}},
+		},
+		{
+			name: "Dirty version",
+			args: args{currentVersion: 3, dirty: true, direction: commonDataSource.MigrateUp()},
+			want: want{err: commonErrors.ErrMigrate},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbmock, queryMock := mockSqlxDriver(commonDataSource.DriverPostgres)
+
+			// only reads are expected, creating the migrations table fails the test
+			if testCase.args.currentVersion == 0 {
+				queryMock.ExpectQuery(postgresMigrationsExistsQuery).WithArgs("schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			} else {
+				queryMock.ExpectQuery(postgresMigrationsExistsQuery).WithArgs("schema_migrations").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				queryMock.ExpectQuery(postgresMigrationVersionQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).
+						AddRow(testCase.args.currentVersion, testCase.args.dirty))
+			}
+
+			versions, err := commonDataSource.MigrateDryRun(context.Background(), dbmock, migrationSource(), testCase.args.direction)
+			if testCase.want.err != nil {
+				assert.ErrorIs(t, err, testCase.want.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.versions, versions)
+			assert.Nil(t, queryMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_MigrateDryRunMysqlCustomTable(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver(commonDataSource.DriverMysql)
+	queryMock.ExpectQuery("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?").
+		WithArgs("loan_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	queryMock.ExpectQuery("SELECT version, dirty FROM `loan_migrations` LIMIT 1").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}))
+
+	versions, err := commonDataSource.MigrateDryRun(context.Background(), dbmock, migrationSource(),
+		commonDataSource.MigrateSteps(2), commonDataSource.WithMigrationsTable("loan_migrations"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{1, 2}, versions)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_MigrateDryRunWithoutSource(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver(commonDataSource.DriverPostgres)
+	queryMock.ExpectQuery(postgresMigrationsExistsQuery).WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, err := commonDataSource.MigrateDryRun(context.Background(), dbmock, commonDataSource.MigrationSource{}, commonDataSource.MigrateUp())
+
+	assert.ErrorIs(t, err, commonErrors.ErrMigrate)
+	assert.ErrorIs(t, err, commonDataSource.ErrMigrationSourceRequired)
+}
+
+func Test_MigrateDryRunDirty(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlxDriver(commonDataSource.DriverPostgres)
+	queryMock.ExpectQuery(postgresMigrationsExistsQuery).WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	queryMock.ExpectQuery(postgresMigrationVersionQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(3, true))
+
+	_, err := commonDataSource.MigrateDryRun(context.Background(), dbmock, migrationSource(), commonDataSource.MigrateUp())
+
+	var errDirty migrate.ErrDirty
+	assert.ErrorIs(t, err, commonErrors.ErrMigrate)
+	assert.ErrorAs(t, err, &errDirty)
+	assert.Equal(t, 3, errDirty.Version)
+}
+
+func Test_MigrateUnsupportedDriver(t *testing.T) {
+	t.Parallel()
+	dbmock, _, err := sqlmock.New()
+	assert.Nil(t, err)
+
+	err = commonDataSource.Migrate(context.Background(), sqlx.NewDb(dbmock, "sqlmock"), commonDataSource.MigrationSource{FS: fstest.MapFS{}}, commonDataSource.MigrateUp())
+	assert.ErrorIs(t, err, commonErrors.ErrMigrate)
+}
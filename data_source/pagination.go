@@ -0,0 +1,511 @@
+package data_source
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	PAGINATION_DEFAULT_LIMIT = 10
+
+	QueryParamPage   = "page"
+	QueryParamLimit  = "limit"
+	QueryParamSort   = "sort"
+	QueryParamCursor = "cursor"
+	QueryParamFilter = "filter"
+)
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+type FilterOperator string
+
+const (
+	FilterEq   FilterOperator = "eq"
+	FilterNe   FilterOperator = "ne"
+	FilterGt   FilterOperator = "gt"
+	FilterGte  FilterOperator = "gte"
+	FilterLt   FilterOperator = "lt"
+	FilterLte  FilterOperator = "lte"
+	FilterLike FilterOperator = "like"
+	FilterIn   FilterOperator = "in"
+)
+
+// PaginationConfig is the whitelist and the limits used by ParsePagination.
+// SortableColumns and FilterableColumns map the query param name to the
+// column expression used in the query, e.g. "createdAt": "u.created_at".
+type PaginationConfig struct {
+	DefaultLimit      uint
+	MaxLimit          uint
+	DefaultSorts      []Sort
+	SortableColumns   map[string]string
+	FilterableColumns map[string]string
+}
+
+type Sort struct {
+	Column    string
+	Direction SortDirection
+}
+
+type Filter struct {
+	Column   string
+	Operator FilterOperator
+	Value    string
+}
+
+// Pagination is the parsed page, limit, sort, filter and cursor of a request.
+type Pagination struct {
+	Page    uint
+	Limit   uint
+	Sorts   []Sort
+	Filters []Filter
+	Cursor  string
+	// isDefaultSorts is set when Sorts are the DefaultSorts of the config,
+	// not sent by the client.
+	isDefaultSorts bool
+}
+
+// Keyset describe the columns used by keyset (cursor) pagination. Column
+// is the paging column and Tiebreaker a unique column, e.g. the primary
+// key, ordering the rows sharing the same Column value; set it to Column
+// when Column is unique. Field and TiebreakerField are the db tags of the
+// destination fields holding the cursor values, they default to the
+// columns without the table qualifier.
+type Keyset struct {
+	Column          string
+	Field           string
+	Tiebreaker      string
+	TiebreakerField string
+	Direction       SortDirection
+}
+
+// ParsePagination parse the page, limit, sort, filter and cursor query
+// params, e.g. ?page=2&limit=20&sort=-createdAt,name&filter[status]=active
+// &filter[amount][gte]=100. Sort and filter outside the whitelist in config
+// return errors.ErrInvalidPagination.
+func ParsePagination(values url.Values, config PaginationConfig) (Pagination, error) {
+	if config.DefaultLimit == 0 {
+		config.DefaultLimit = PAGINATION_DEFAULT_LIMIT
+	}
+	if config.MaxLimit == 0 {
+		config.MaxLimit = GET_DATA_DEFAULT_LIMIT
+	}
+
+	p := Pagination{
+		Page:           1,
+		Limit:          config.DefaultLimit,
+		Sorts:          config.DefaultSorts,
+		Cursor:         values.Get(QueryParamCursor),
+		isDefaultSorts: len(config.DefaultSorts) > 0,
+	}
+
+	if page := values.Get(QueryParamPage); page != "" {
+		val, err := strconv.ParseUint(page, 10, 32)
+		if err != nil || val == 0 {
+			return Pagination{}, commonErrors.ErrInvalidPagination
+		}
+		p.Page = uint(val)
+	}
+
+	if limit := values.Get(QueryParamLimit); limit != "" {
+		val, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil || val == 0 {
+			return Pagination{}, commonErrors.ErrInvalidPagination
+		}
+		p.Limit = uint(val)
+	}
+	if p.Limit > config.MaxLimit {
+		p.Limit = config.MaxLimit
+	}
+
+	if sort := values.Get(QueryParamSort); sort != "" {
+		sorts, err := parseSorts(sort, config.SortableColumns)
+		if err != nil {
+			return Pagination{}, err
+		}
+		p.Sorts = sorts
+		p.isDefaultSorts = false
+	}
+
+	filters, err := parseFilters(values, config.FilterableColumns)
+	if err != nil {
+		return Pagination{}, err
+	}
+	p.Filters = filters
+
+	return p, nil
+}
+
+func parseSorts(sort string, sortableColumns map[string]string) ([]Sort, error) {
+	sorts := make([]Sort, 0)
+	for _, param := range strings.Split(sort, ",") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+
+		direction := SortAsc
+		if strings.HasPrefix(param, "-") {
+			direction = SortDesc
+			param = param[1:]
+		}
+
+		column, ok := sortableColumns[param]
+		if !ok {
+			return nil, commonErrors.ErrInvalidPagination
+		}
+		sorts = append(sorts, Sort{Column: column, Direction: direction})
+	}
+
+	return sorts, nil
+}
+
+// parseFilters parse filter[name]=value and filter[name][operator]=value.
+func parseFilters(values url.Values, filterableColumns map[string]string) ([]Filter, error) {
+	filters := make([]Filter, 0)
+	for key, vals := range values {
+		if !strings.HasPrefix(key, QueryParamFilter+"[") || len(vals) == 0 {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, QueryParamFilter+"["), "]"), "][")
+		if len(parts) > 2 {
+			return nil, commonErrors.ErrInvalidPagination
+		}
+
+		column, ok := filterableColumns[parts[0]]
+		if !ok {
+			return nil, commonErrors.ErrInvalidPagination
+		}
+
+		operator := FilterEq
+		if len(parts) == 2 {
+			operator = FilterOperator(parts[1])
+		}
+
+		switch operator {
+		case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike, FilterIn:
+		default:
+			return nil, commonErrors.ErrInvalidPagination
+		}
+
+		filters = append(filters, Filter{Column: column, Operator: operator, Value: vals[0]})
+	}
+
+	// map iteration is random, keep the generated query stable
+	slices.SortFunc(filters, func(a, b Filter) bool {
+		return a.Column+":"+string(a.Operator) < b.Column+":"+string(b.Operator)
+	})
+
+	return filters, nil
+}
+
+// Offset return the offset of the current page.
+func (p Pagination) Offset() uint64 {
+	if p.Page == 0 {
+		return 0
+	}
+	return uint64(p.Page-1) * uint64(p.Limit)
+}
+
+// ApplyFilters add the filters of the pagination to the where clause.
+func (p Pagination) ApplyFilters(builder sq.SelectBuilder) sq.SelectBuilder {
+	for _, filter := range p.Filters {
+		switch filter.Operator {
+		case FilterNe:
+			builder = builder.Where(sq.NotEq{filter.Column: filter.Value})
+		case FilterGt:
+			builder = builder.Where(sq.Gt{filter.Column: filter.Value})
+		case FilterGte:
+			builder = builder.Where(sq.GtOrEq{filter.Column: filter.Value})
+		case FilterLt:
+			builder = builder.Where(sq.Lt{filter.Column: filter.Value})
+		case FilterLte:
+			builder = builder.Where(sq.LtOrEq{filter.Column: filter.Value})
+		case FilterLike:
+			// backslash is the default LIKE escape of postgres and mysql
+			builder = builder.Where(sq.Like{filter.Column: "%" + likeEscaper.Replace(filter.Value) + "%"})
+		case FilterIn:
+			values := make([]string, 0)
+			for _, value := range strings.Split(filter.Value, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			// an empty in filters nothing rather than matching ''
+			if len(values) > 0 {
+				builder = builder.Where(sq.Eq{filter.Column: values})
+			}
+		default:
+			builder = builder.Where(sq.Eq{filter.Column: filter.Value})
+		}
+	}
+
+	return builder
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ApplySorts add the sorts of the pagination to the order by clause.
+func (p Pagination) ApplySorts(builder sq.SelectBuilder) sq.SelectBuilder {
+	for _, sort := range p.Sorts {
+		builder = builder.OrderBy(fmt.Sprintf("%s %s", sort.Column, sort.Direction))
+	}
+
+	return builder
+}
+
+// Apply add filters, sorts, limit and offset of the pagination to builder.
+func (p Pagination) Apply(builder sq.SelectBuilder) sq.SelectBuilder {
+	return p.ApplySorts(p.ApplyFilters(builder)).
+		Limit(uint64(p.Limit)).
+		Offset(p.Offset())
+}
+
+// ApplyKeyset add filters and keyset pagination to builder, ordered by the
+// Column and Tiebreaker of keyset. It fetches one extra row so
+// PaginateKeyset can tell whether there is a next page. The sorts sent by
+// the client can't be combined with the keyset order and return
+// errors.ErrInvalidPagination, the DefaultSorts of the config are ignored.
+func (p Pagination) ApplyKeyset(builder sq.SelectBuilder, keyset Keyset) (sq.SelectBuilder, error) {
+	if keyset.Column == "" || keyset.Tiebreaker == "" {
+		return builder, fmt.Errorf("keyset column and tiebreaker are required")
+	}
+	if len(p.Sorts) > 0 && !p.isDefaultSorts {
+		return builder, commonErrors.ErrInvalidPagination
+	}
+
+	builder = p.ApplyFilters(builder)
+
+	isUnique := keyset.Column == keyset.Tiebreaker
+	if p.Cursor != "" {
+		cursor, err := DecodeCursor(p.Cursor)
+		if err != nil || (isUnique && len(cursor) != 1) || (!isUnique && len(cursor) != 2) {
+			return builder, commonErrors.ErrInvalidPagination
+		}
+
+		// (column, tiebreaker) > (cursor) expanded, supported by every driver
+		after := func(column string, value any) sq.Sqlizer {
+			if keyset.Direction == SortDesc {
+				return sq.Lt{column: value}
+			}
+			return sq.Gt{column: value}
+		}
+		if isUnique {
+			builder = builder.Where(after(keyset.Column, cursor[0]))
+		} else {
+			builder = builder.Where(sq.Or{
+				after(keyset.Column, cursor[0]),
+				sq.And{sq.Eq{keyset.Column: cursor[0]}, after(keyset.Tiebreaker, cursor[1])},
+			})
+		}
+	}
+
+	direction := keyset.Direction
+	if direction == "" {
+		direction = SortAsc
+	}
+
+	builder = builder.OrderBy(fmt.Sprintf("%s %s", keyset.Column, direction))
+	if !isUnique {
+		builder = builder.OrderBy(fmt.Sprintf("%s %s", keyset.Tiebreaker, direction))
+	}
+
+	return builder.Limit(uint64(p.Limit) + 1), nil
+}
+
+// PageInfo build the pagination fields of the response from total records.
+func (p Pagination) PageInfo(totalRecords uint64) responseModel.PageInfo {
+	info := responseModel.PageInfo{
+		Limit:        p.Limit,
+		TotalRecords: totalRecords,
+		CurrentPage:  p.Page,
+	}
+
+	if p.Limit > 0 {
+		info.TotalPages = uint((totalRecords + uint64(p.Limit) - 1) / uint64(p.Limit))
+	}
+	if p.Page > 1 {
+		info.PreviousPage = p.Page - 1
+	}
+	if p.Page < info.TotalPages {
+		info.NextPage = p.Page + 1
+	}
+
+	return info
+}
+
+// Paginate run the count query and the page query of builder with offset
+// pagination, scan the page into dest and return the pagination fields.
+// builder must select the columns of dest without limit, offset and order.
+func Paginate(ctx context.Context, db *sqlx.DB, builder sq.SelectBuilder, p Pagination, dest any) (responseModel.PageInfo, error) {
+	filtered := p.ApplyFilters(builder).PlaceholderFormat(sq.Question)
+
+	countQuery, countArgs, err := sq.Select("COUNT(*)").FromSelect(filtered, "paginated").ToSql()
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	pageQuery, pageArgs, err := p.Apply(builder).PlaceholderFormat(sq.Question).ToSql()
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	var totalRecords uint64
+	err = Exec(ctx, db,
+		NewStatement(&totalRecords, db.Rebind(countQuery), countArgs...),
+		NewStatement(dest, db.Rebind(pageQuery), pageArgs...),
+	)
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	return p.PageInfo(totalRecords), nil
+}
+
+// PaginateKeyset run the page query of builder with keyset pagination, scan
+// the page into dest and return the limit and the cursor of the next page.
+// dest must be a pointer to a slice of struct.
+func PaginateKeyset(ctx context.Context, db *sqlx.DB, builder sq.SelectBuilder, p Pagination, keyset Keyset, dest any) (responseModel.PageInfo, error) {
+	builder, err := p.ApplyKeyset(builder, keyset)
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	query, args, err := builder.PlaceholderFormat(sq.Question).ToSql()
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	err = Exec(ctx, db, NewStatement(dest, db.Rebind(query), args...))
+	if err != nil {
+		return responseModel.PageInfo{}, err
+	}
+
+	info := responseModel.PageInfo{Limit: p.Limit}
+
+	slice := reflect.ValueOf(dest).Elem()
+	if slice.Len() <= int(p.Limit) {
+		return info, nil
+	}
+
+	// drop the extra row, its existence means there is a next page
+	slice.SetLen(int(p.Limit))
+
+	last := slice.Index(slice.Len() - 1)
+	value, err := keysetValue(last, keyset.Column, keyset.Field)
+	if err != nil {
+		return info, err
+	}
+	values := []any{value}
+	if keyset.Tiebreaker != keyset.Column {
+		tiebreaker, err := keysetValue(last, keyset.Tiebreaker, keyset.TiebreakerField)
+		if err != nil {
+			return info, err
+		}
+		values = append(values, tiebreaker)
+	}
+
+	info.NextCursor, err = EncodeCursor(values...)
+	if err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+// keysetValue return the value of the field with db tag field, the column
+// without the table qualifier by default.
+func keysetValue(row reflect.Value, column, field string) (any, error) {
+	if field == "" {
+		field = column[strings.LastIndex(column, ".")+1:]
+	}
+
+	value, ok := fieldByDbTag(row, field)
+	if !ok {
+		return nil, fmt.Errorf("keyset field %s not found in destination", field)
+	}
+
+	return value, nil
+}
+
+func fieldByDbTag(v reflect.Value, tag string) (any, bool) {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("db") == tag {
+			return v.Field(i).Interface(), true
+		}
+	}
+
+	return nil, false
+}
+
+// EncodeCursor encode the keyset values of the last row into an opaque
+// cursor. The values are encoded as json, the times as RFC3339Nano in UTC
+// so they compare back in SQL.
+func EncodeCursor(values ...any) (string, error) {
+	encoded := make([]any, 0, len(values))
+	for _, value := range values {
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			if value, err = valuer.Value(); err != nil {
+				return "", err
+			}
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+		encoded = append(encoded, value)
+	}
+
+	raw, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor decode the values of the cursor produced by EncodeCursor,
+// the numbers are json.Number to keep the precision of the big ids.
+func DecodeCursor(cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,385 @@
+package data_source_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+)
+
+var paginationConfig = commonDataSource.PaginationConfig{
+	MaxLimit:          50,
+	SortableColumns:   map[string]string{"name": "u.name", "createdAt": "u.created_at"},
+	FilterableColumns: map[string]string{"status": "u.status", "amount": "u.amount"},
+}
+
+func Test_ParsePagination(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			query string
+		}
+		want struct {
+			pagination commonDataSource.Pagination
+			err        error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Default value",
+			args: args{query: ""},
+			want: want{
+				pagination: commonDataSource.Pagination{
+					Page:    1,
+					Limit:   commonDataSource.PAGINATION_DEFAULT_LIMIT,
+					Filters: []commonDataSource.Filter{},
+				},
+			},
+		},
+		{
+			name: "Page, limit, sort and filter",
+			args: args{query: "page=2&limit=20&sort=-createdAt,name&filter[status]=active&filter[amount][gte]=100"},
+			want: want{
+				pagination: commonDataSource.Pagination{
+					Page:  2,
+					Limit: 20,
+					Sorts: []commonDataSource.Sort{
+						{Column: "u.created_at", Direction: commonDataSource.SortDesc},
+						{Column: "u.name", Direction: commonDataSource.SortAsc},
+					},
+					Filters: []commonDataSource.Filter{
+						{Column: "u.amount", Operator: commonDataSource.FilterGte, Value: "100"},
+						{Column: "u.status", Operator: commonDataSource.FilterEq, Value: "active"},
+					},
+				},
+			},
+		},
+		{
+			name: "Limit above max limit",
+			args: args{query: "limit=1000"},
+			want: want{
+				pagination: commonDataSource.Pagination{
+					Page:    1,
+					Limit:   50,
+					Filters: []commonDataSource.Filter{},
+				},
+			},
+		},
+		{
+			name: "Invalid page",
+			args: args{query: "page=abc"},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+		{
+			name: "Sort column not allowed",
+			args: args{query: "sort=password"},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+		{
+			name: "Filter column not allowed",
+			args: args{query: "filter[password]=secret"},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+		{
+			name: "Filter operator not allowed",
+			args: args{query: "filter[status][regex]=.*"},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			values, err := url.ParseQuery(testCase.args.query)
+			assert.Nil(t, err)
+
+			pagination, err := commonDataSource.ParsePagination(values, paginationConfig)
+			if testCase.want.err != nil {
+				assert.ErrorIs(t, err, testCase.want.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.pagination, pagination)
+		})
+	}
+}
+
+func Test_PaginationPageInfo(t *testing.T) {
+	t.Parallel()
+	pagination := commonDataSource.Pagination{Page: 2, Limit: 10}
+
+	assert.Equal(t, responseModel.PageInfo{
+		Limit:        10,
+		TotalRecords: 25,
+		CurrentPage:  2,
+		NextPage:     3,
+		PreviousPage: 1,
+		TotalPages:   3,
+	}, pagination.PageInfo(25))
+}
+
+func Test_Paginate(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	countQuery := "SELECT COUNT(*) FROM (SELECT id, name FROM users WHERE u.status = ?) AS paginated"
+	pageQuery := "SELECT id, name FROM users WHERE u.status = ? ORDER BY u.name ASC LIMIT 10 OFFSET 10"
+
+	queryMock.ExpectPrepare(countQuery).ExpectQuery().WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	queryMock.ExpectPrepare(pageQuery).ExpectQuery().WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(11, "John").AddRow(12, "Jane"))
+
+	pagination := commonDataSource.Pagination{
+		Page:    2,
+		Limit:   10,
+		Sorts:   []commonDataSource.Sort{{Column: "u.name", Direction: commonDataSource.SortAsc}},
+		Filters: []commonDataSource.Filter{{Column: "u.status", Operator: commonDataSource.FilterEq, Value: "active"}},
+	}
+
+	var dest []struct {
+		Id   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	info, err := commonDataSource.Paginate(
+		context.Background(), dbmock, sq.Select("id", "name").From("users"), pagination, &dest,
+	)
+
+	assert.Nil(t, err)
+	assert.Len(t, dest, 2)
+	assert.Equal(t, responseModel.PageInfo{
+		Limit:        10,
+		TotalRecords: 12,
+		CurrentPage:  2,
+		PreviousPage: 1,
+		TotalPages:   2,
+	}, info)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_PaginateKeyset(t *testing.T) {
+	t.Parallel()
+	type (
+		row struct {
+			Id        int       `db:"id"`
+			CreatedAt time.Time `db:"created_at"`
+		}
+		args struct {
+			keyset     commonDataSource.Keyset
+			pagination commonDataSource.Pagination
+			rows       *sqlmock.Rows
+		}
+		want struct {
+			query      string
+			queryArgs  []driver.Value
+			nextCursor []any
+			err        error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	mustEncode := func(values ...any) string {
+		cursor, err := commonDataSource.EncodeCursor(values...)
+		assert.Nil(t, err)
+		return cursor
+	}
+
+	testCases := []testCase{
+		{
+			name: "Unique column",
+			args: args{
+				keyset:     commonDataSource.Keyset{Column: "id", Tiebreaker: "id"},
+				pagination: commonDataSource.Pagination{Limit: 2, Cursor: mustEncode(10)},
+				rows: sqlmock.NewRows([]string{"id", "created_at"}).
+					AddRow(11, createdAt).AddRow(12, createdAt).AddRow(13, createdAt),
+			},
+			want: want{
+				query:      "SELECT id, created_at FROM users WHERE id > ? ORDER BY id ASC LIMIT 3",
+				queryArgs:  []driver.Value{"10"},
+				nextCursor: []any{12},
+			},
+		},
+		{
+			name: "Non unique column with tiebreaker",
+			args: args{
+				keyset: commonDataSource.Keyset{Column: "u.created_at", Tiebreaker: "u.id", Direction: commonDataSource.SortDesc},
+				pagination: commonDataSource.Pagination{
+					Limit:  2,
+					Cursor: mustEncode(createdAt, 10),
+				},
+				rows: sqlmock.NewRows([]string{"id", "created_at"}).
+					AddRow(9, createdAt).AddRow(8, createdAt).AddRow(7, createdAt),
+			},
+			want: want{
+				query: "SELECT id, created_at FROM users WHERE (u.created_at < ? OR (u.created_at = ? AND u.id < ?)) " +
+					"ORDER BY u.created_at DESC, u.id DESC LIMIT 3",
+				queryArgs:  []driver.Value{"2023-05-01T10:00:00.123456789Z", "2023-05-01T10:00:00.123456789Z", "10"},
+				nextCursor: []any{createdAt, 8},
+			},
+		},
+		{
+			name: "Without tiebreaker",
+			args: args{
+				keyset:     commonDataSource.Keyset{Column: "u.created_at"},
+				pagination: commonDataSource.Pagination{Limit: 2},
+			},
+			want: want{err: errors.New("keyset column and tiebreaker are required")},
+		},
+		{
+			name: "With sorts",
+			args: args{
+				keyset: commonDataSource.Keyset{Column: "id", Tiebreaker: "id"},
+				pagination: commonDataSource.Pagination{
+					Limit: 2,
+					Sorts: []commonDataSource.Sort{{Column: "u.name", Direction: commonDataSource.SortAsc}},
+				},
+			},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+		{
+			name: "Cursor of another keyset",
+			args: args{
+				keyset:     commonDataSource.Keyset{Column: "u.created_at", Tiebreaker: "u.id"},
+				pagination: commonDataSource.Pagination{Limit: 2, Cursor: mustEncode(10)},
+			},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbmock, queryMock := mockSqlx()
+			if testCase.want.query != "" {
+				queryMock.ExpectPrepare(testCase.want.query).ExpectQuery().
+					WithArgs(testCase.want.queryArgs...).
+					WillReturnRows(testCase.args.rows)
+			}
+
+			var dest []row
+			info, err := commonDataSource.PaginateKeyset(
+				context.Background(), dbmock, sq.Select("id", "created_at").From("users"),
+				testCase.args.pagination, testCase.args.keyset, &dest,
+			)
+			if testCase.want.err != nil {
+				assert.Equal(t, testCase.want.err.Error(), err.Error())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Len(t, dest, 2)
+			assert.Equal(t, mustEncode(testCase.want.nextCursor...), info.NextCursor)
+			assert.Nil(t, queryMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_EncodeCursor(t *testing.T) {
+	t.Parallel()
+	createdAt := time.Date(2023, 5, 1, 17, 0, 0, 5, time.FixedZone("WIB", 7*60*60))
+
+	cursor, err := commonDataSource.EncodeCursor(createdAt, int64(9007199254740993))
+	assert.Nil(t, err)
+
+	values, err := commonDataSource.DecodeCursor(cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, []any{"2023-05-01T10:00:00.000000005Z", json.Number("9007199254740993")}, values)
+}
+
+func Test_ApplyFiltersEmptyIn(t *testing.T) {
+	t.Parallel()
+	pagination := commonDataSource.Pagination{Filters: []commonDataSource.Filter{
+		{Column: "u.status", Operator: commonDataSource.FilterIn, Value: ""},
+		{Column: "u.type", Operator: commonDataSource.FilterIn, Value: "a, ,b"},
+	}}
+
+	query, args, err := pagination.ApplyFilters(sq.Select("id").From("users")).ToSql()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE u.type IN (?,?)", query)
+	assert.Equal(t, []any{"a", "b"}, args)
+}
+
+func Test_ApplyKeysetDefaultSorts(t *testing.T) {
+	t.Parallel()
+	config := paginationConfig
+	config.DefaultSorts = []commonDataSource.Sort{{Column: "u.created_at", Direction: commonDataSource.SortDesc}}
+	type (
+		args struct {
+			query string
+		}
+		want struct {
+			query string
+			err   error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Default sorts are ignored",
+			args: args{query: "limit=2"},
+			want: want{query: "SELECT id FROM users ORDER BY id ASC LIMIT 3"},
+		},
+		{
+			name: "Sorts sent by the client",
+			args: args{query: "limit=2&sort=name"},
+			want: want{err: commonErrors.ErrInvalidPagination},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			values, err := url.ParseQuery(testCase.args.query)
+			assert.Nil(t, err)
+			pagination, err := commonDataSource.ParsePagination(values, config)
+			assert.Nil(t, err)
+
+			builder, err := pagination.ApplyKeyset(sq.Select("id").From("users"),
+				commonDataSource.Keyset{Column: "id", Tiebreaker: "id"})
+			if testCase.want.err != nil {
+				assert.ErrorIs(t, err, testCase.want.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			query, _, err := builder.ToSql()
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.query, query)
+		})
+	}
+}
+
+func Test_ApplyFiltersLikeEscaped(t *testing.T) {
+	t.Parallel()
+	pagination := commonDataSource.Pagination{Filters: []commonDataSource.Filter{
+		{Column: "u.name", Operator: commonDataSource.FilterLike, Value: `50%_off\`},
+	}}
+
+	query, args, err := pagination.ApplyFilters(sq.Select("id").From("users")).ToSql()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE u.name LIKE ?", query)
+	assert.Equal(t, []any{`%50\%\_off\\%`}, args)
+}
@@ -0,0 +1,267 @@
+package data_source
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	STATEMENT_CACHE_DEFAULT_SIZE = 100
+
+	// pqErrInvalidStatementName is returned by postgres when the prepared
+	// statement doesn't exist anymore on the server, e.g. after a failover.
+	pqErrInvalidStatementName = "26000"
+	// mysqlErrUnknownStmtHandler is the mysql equivalent, "Unknown prepared
+	// statement handler".
+	mysqlErrUnknownStmtHandler = 1243
+)
+
+// dbOption is the statement execution settings of a connection, kept until
+// CloseDB; closing the db with db.Close keep them in dbOptions.
+type dbOption struct {
+	hooks         []QueryHook
+	stmtCache     *statementCache
+	bypassPrepare bool
+}
+
+var dbOptions = struct {
+	mu      sync.RWMutex
+	options map[*sqlx.DB]dbOption
+}{options: make(map[*sqlx.DB]dbOption)}
+
+func getDBOption(db *sqlx.DB) dbOption {
+	dbOptions.mu.RLock()
+	defer dbOptions.mu.RUnlock()
+
+	return dbOptions.options[db]
+}
+
+func updateDBOption(db *sqlx.DB, fn func(option *dbOption)) {
+	dbOptions.mu.Lock()
+	defer dbOptions.mu.Unlock()
+
+	option := dbOptions.options[db]
+	fn(&option)
+
+	// don't keep the db once it has no settings anymore
+	if len(option.hooks) == 0 && option.stmtCache == nil && !option.bypassPrepare {
+		delete(dbOptions.options, db)
+		return
+	}
+	dbOptions.options[db] = option
+}
+
+// CloseDB remove the hooks, the statement cache and the prepare settings
+// of db, closing its cached statements, then close db. Use it instead of
+// db.Close when any of them is set, e.g. by NewDB.
+func CloseDB(db *sqlx.DB) error {
+	dbOptions.mu.Lock()
+	option := dbOptions.options[db]
+	delete(dbOptions.options, db)
+	dbOptions.mu.Unlock()
+
+	if option.stmtCache != nil {
+		option.stmtCache.close()
+	}
+
+	return db.Close()
+}
+
+// EnableStatementCache keep up to size prepared statements of db, the least
+// recently used statement is closed when the cache is full. size <= 0 means
+// STATEMENT_CACHE_DEFAULT_SIZE. Close db with CloseDB, the cached
+// statements are kept otherwise.
+func EnableStatementCache(db *sqlx.DB, size int) {
+	if size <= 0 {
+		size = STATEMENT_CACHE_DEFAULT_SIZE
+	}
+
+	var previous *statementCache
+	updateDBOption(db, func(option *dbOption) {
+		previous = option.stmtCache
+		option.stmtCache = newStatementCache(size)
+	})
+
+	if previous != nil {
+		previous.close()
+	}
+}
+
+// DisableStatementCache close the cached statements of db, the next
+// statements are prepared on every execution again.
+func DisableStatementCache(db *sqlx.DB) {
+	var previous *statementCache
+	updateDBOption(db, func(option *dbOption) {
+		previous = option.stmtCache
+		option.stmtCache = nil
+	})
+
+	if previous != nil {
+		previous.close()
+	}
+}
+
+// BypassPrepare run the statements of db without preparing them, required
+// by PgBouncer in transaction pooling mode. It takes precedence over the
+// statement cache. Close db with CloseDB, the setting is kept otherwise.
+func BypassPrepare(db *sqlx.DB, bypass bool) {
+	updateDBOption(db, func(option *dbOption) {
+		option.bypassPrepare = bypass
+	})
+}
+
+// preparedExecutor is implemented by *sqlx.Stmt and unpreparedStmt.
+type preparedExecutor interface {
+	ExecContext(ctx context.Context, args ...any) (sql.Result, error)
+	SelectContext(ctx context.Context, dest any, args ...any) error
+	GetContext(ctx context.Context, dest any, args ...any) error
+}
+
+// unpreparedStmt send the query with its args directly to the database.
+type unpreparedStmt struct {
+	ext   sqlx.ExtContext
+	query string
+}
+
+func (u unpreparedStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return u.ext.ExecContext(ctx, u.query, args...)
+}
+
+func (u unpreparedStmt) SelectContext(ctx context.Context, dest any, args ...any) error {
+	return sqlx.SelectContext(ctx, u.ext, dest, u.query, args...)
+}
+
+func (u unpreparedStmt) GetContext(ctx context.Context, dest any, args ...any) error {
+	return sqlx.GetContext(ctx, u.ext, dest, u.query, args...)
+}
+
+type cachedStatement struct {
+	query   string
+	stmt    *sqlx.Stmt
+	refs    int
+	evicted bool
+}
+
+// statementCache is a LRU cache of prepared statements keyed by query.
+// Evicted statements are closed once no execution use them anymore.
+type statementCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+func newStatementCache(size int) *statementCache {
+	return &statementCache{
+		size:  size,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// acquire return the prepared statement of query, preparing it when it's
+// not cached yet. Call release when the statement is not used anymore.
+func (c *statementCache) acquire(ctx context.Context, db *sqlx.DB, query string) (*cachedStatement, error) {
+	c.mu.Lock()
+	if element, ok := c.items[query]; ok {
+		c.lru.MoveToFront(element)
+		cached := element.Value.(*cachedStatement)
+		cached.refs++
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// prepared concurrently by another execution
+	if element, ok := c.items[query]; ok {
+		_ = stmt.Close()
+		c.lru.MoveToFront(element)
+		cached := element.Value.(*cachedStatement)
+		cached.refs++
+		return cached, nil
+	}
+
+	cached := &cachedStatement{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.lru.PushFront(cached)
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+	}
+
+	return cached, nil
+}
+
+func (c *statementCache) release(cached *cachedStatement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached.refs--
+	if cached.evicted && cached.refs == 0 {
+		_ = cached.stmt.Close()
+	}
+}
+
+// remove evict the statement of query, used when it's not valid anymore.
+func (c *statementCache) remove(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[query]; ok {
+		c.evict(element)
+	}
+}
+
+func (c *statementCache) evict(element *list.Element) {
+	cached := element.Value.(*cachedStatement)
+	c.lru.Remove(element)
+	delete(c.items, cached.query)
+
+	cached.evicted = true
+	if cached.refs == 0 {
+		_ = cached.stmt.Close()
+	}
+}
+
+func (c *statementCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
+
+// isStaleStatementErr report whether the prepared statement doesn't exist
+// on the server anymore, the statement wasn't run so it's safe to prepare
+// it again and retry. The broken connections aren't retried, a write may
+// have been applied before the connection broke; database/sql already
+// prepare the statement again on another connection.
+func isStaleStatementErr(err error) bool {
+	var (
+		psqlErr  *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+
+	switch {
+	case errors.As(err, &psqlErr):
+		return psqlErr.Code == pqErrInvalidStatementName
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == mysqlErrUnknownStmtHandler
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package data_source_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"bitbucket.org/moladinTech/go-lib-common/data_source/datasourcetest"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StatementCache(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 1)
+	defer commonDataSource.DisableStatementCache(dbmock)
+
+	usersQuery := "SELECT id FROM users"
+	rolesQuery := "SELECT id FROM roles"
+	usersPrepare := queryMock.ExpectPrepare(usersQuery).WillBeClosed()
+	usersPrepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	usersPrepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	queryMock.ExpectPrepare(rolesQuery).ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	var first, second, roles []int
+	err := commonDataSource.Exec(context.Background(), dbmock,
+		commonDataSource.NewStatement(&first, usersQuery),
+		commonDataSource.NewStatement(&second, usersQuery), // reuse the prepared statement
+		commonDataSource.NewStatement(&roles, rolesQuery),  // evict and close the users statement
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, first)
+	assert.Equal(t, []int{2}, second)
+	assert.Equal(t, []int{3}, roles)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_StatementCacheStaleStatement(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 0)
+	defer commonDataSource.DisableStatementCache(dbmock)
+
+	query := "SELECT id FROM users"
+	queryMock.ExpectPrepare(query).WillBeClosed().ExpectQuery().
+		WillReturnError(&pq.Error{Code: "26000"})
+	queryMock.ExpectPrepare(query).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var ids []int
+	err := commonDataSource.Exec(context.Background(), dbmock, commonDataSource.NewStatement(&ids, query))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, ids)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_StatementCacheStaleStatementMysql(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 0)
+	defer commonDataSource.DisableStatementCache(dbmock)
+
+	query := "SELECT id FROM users"
+	queryMock.ExpectPrepare(query).WillBeClosed().ExpectQuery().
+		WillReturnError(datasourcetest.MysqlError(1243, "Unknown prepared statement handler"))
+	queryMock.ExpectPrepare(query).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var ids []int
+	err := commonDataSource.Exec(context.Background(), dbmock, commonDataSource.NewStatement(&ids, query))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, ids)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_StatementCacheWriteNotRetried(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 0)
+	defer commonDataSource.DisableStatementCache(dbmock)
+
+	// the insert may be applied before the connection broke, it must run once
+	query := "INSERT INTO users (name) VALUES (?)"
+	queryMock.ExpectPrepare(query).ExpectExec().WithArgs("John").
+		WillReturnError(sql.ErrConnDone)
+
+	err := commonDataSource.Exec(context.Background(), dbmock, commonDataSource.NewStatement(nil, query, "John"))
+
+	assert.ErrorContains(t, err, sql.ErrConnDone.Error())
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_CloseDB(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 0)
+
+	query := "SELECT id FROM users"
+	queryMock.ExpectPrepare(query).WillBeClosed().ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	queryMock.ExpectClose()
+
+	var ids []int
+	err := commonDataSource.Exec(context.Background(), dbmock, commonDataSource.NewStatement(&ids, query))
+	assert.Nil(t, err)
+
+	// the cached statement is closed before the db
+	assert.Nil(t, commonDataSource.CloseDB(dbmock))
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_BypassPrepare(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.BypassPrepare(dbmock, true)
+
+	query := "UPDATE users SET name = ? WHERE id = ?"
+	queryMock.ExpectBegin()
+	queryMock.ExpectExec(query).WithArgs("John", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	queryMock.ExpectCommit()
+
+	err := commonDataSource.ExecTx(context.Background(), dbmock, commonDataSource.NewStatement(nil, query, "John", 1))
+
+	assert.Nil(t, err)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_StatementCacheTx(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	commonDataSource.EnableStatementCache(dbmock, 0)
+	defer commonDataSource.DisableStatementCache(dbmock)
+
+	query := "UPDATE users SET name = ? WHERE id = ?"
+	queryMock.ExpectBegin()
+	queryMock.ExpectPrepare(query)
+	// database/sql prepare again on the tx connection when it differ from the cached one
+	queryMock.ExpectPrepare(query).ExpectExec().WithArgs("John", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	queryMock.ExpectCommit()
+
+	err := commonDataSource.ExecTx(context.Background(), dbmock, commonDataSource.NewStatement(nil, query, "John", 1))
+
+	assert.Nil(t, err)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
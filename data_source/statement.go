@@ -19,12 +19,13 @@ type Statement struct {
 	query       string
 	args        []any
 	enableDebug bool
+	redacted    map[int]struct{}
 	mu          *sync.Mutex
 }
 
 // NewStatement creating new pipeline statement.
 func NewStatement(dest any, query string, args ...any) *Statement {
-	return &Statement{dest, query, args, false, &sync.Mutex{}}
+	return &Statement{dest, query, args, false, nil, &sync.Mutex{}}
 }
 
 func (s *Statement) SetDestination(dest any) *Statement {
@@ -66,10 +67,39 @@ func (s *Statement) GetArgs() []any {
 	return s.args
 }
 
+// Redact hide the args at the indexes from the debug log and QueryEvent.
+func (s *Statement) Redact(indexes ...int) *Statement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.redacted == nil {
+		s.redacted = make(map[int]struct{}, len(indexes))
+	}
+	for _, index := range indexes {
+		s.redacted[index] = struct{}{}
+	}
+
+	return s
+}
+
+// GetRedactedArgs return a copy of the args with the redacted args
+// replaced by RedactedArg.
+func (s *Statement) GetRedactedArgs() []any {
+	args := make([]any, len(s.args))
+	for i, arg := range s.args {
+		if _, ok := s.redacted[i]; ok {
+			arg = RedactedArg
+		}
+		args[i] = arg
+	}
+
+	return args
+}
+
 func (s *Statement) log(ctx context.Context) {
 	logger.Debug(ctx, "statement debug",
 		logger.Tag{Key: "query", Value: s.GetQuery()},
-		logger.Tag{Key: "args", Value: fmt.Sprintf("%v", s.GetArgs())},
+		logger.Tag{Key: "args", Value: fmt.Sprintf("%v", s.GetRedactedArgs())},
 		logger.Tag{Key: "dest", Value: fmt.Sprintf("%v", s.GetDestination())},
 	)
 }
@@ -83,59 +113,138 @@ func (s *Statement) Debug() *Statement {
 	return s
 }
 
-// exec Execute the statement within supplied transaction and update the
-// destination if not nil.
-func (s *Statement) exec(ctx context.Context, stmt *sqlx.Stmt) error {
-	defer stmt.Close()
-	var err error
-
-	// destination nil it's mean statement doesn't need result
+func (s *Statement) operation() QueryOperation {
 	if s.GetDestination() == nil {
-		_, err = stmt.ExecContext(ctx, s.GetArgs()...)
-		return err
+		return QueryExec
 	}
 
-	rt := reflect.TypeOf(s.GetDestination())
-	switch rt.Elem().Kind() {
+	switch reflect.TypeOf(s.GetDestination()).Elem().Kind() {
 	case reflect.Slice, reflect.Array:
-		err = stmt.SelectContext(ctx, s.GetDestination(), s.GetArgs()...)
+		return QuerySelect
+	default:
+		return QueryGet
+	}
+}
+
+// exec Execute the statement within supplied transaction and update the
+// destination if not nil. It returns the rows affected, or the rows
+// scanned when the destination is not nil.
+func (s *Statement) exec(ctx context.Context, stmt preparedExecutor) (int64, error) {
+	var rowsAffected int64
+
+	switch s.operation() {
+	// destination nil it's mean statement doesn't need result
+	case QueryExec:
+		result, err := stmt.ExecContext(ctx, s.GetArgs()...)
+		if err != nil {
+			return 0, err
+		}
+		// not every driver support rows affected
+		rowsAffected, _ = result.RowsAffected()
+		return rowsAffected, nil
+	case QuerySelect:
+		err := stmt.SelectContext(ctx, s.GetDestination(), s.GetArgs()...)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		break
+		rowsAffected = int64(reflect.ValueOf(s.GetDestination()).Elem().Len())
 	default:
-		err = stmt.GetContext(ctx, s.GetDestination(), s.GetArgs()...)
+		err := stmt.GetContext(ctx, s.GetDestination(), s.GetArgs()...)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		break
+		rowsAffected = 1
 	}
 
 	if s.enableDebug {
 		s.log(ctx)
 	}
 
-	return err
+	return rowsAffected, nil
+}
+
+func (s *Statement) queryEvent(inTx bool) *QueryEvent {
+	return &QueryEvent{
+		Operation: s.operation(),
+		Query:     s.GetQuery(),
+		Args:      s.GetRedactedArgs(),
+		InTx:      inTx,
+	}
 }
 
 // run Execute the statement without transaction within supplied
 // query and update destination if not nil.
 func (s *Statement) run(ctx context.Context, db *sqlx.DB) error {
-	stmt, err := db.PreparexContext(ctx, s.GetQuery())
-	if err != nil {
-		return err
-	}
+	option := getDBOption(db)
+	return runQueryHooks(ctx, option.hooks, s.queryEvent(false), func(ctx context.Context) (int64, error) {
+		switch {
+		case option.bypassPrepare:
+			return s.exec(ctx, unpreparedStmt{ext: db, query: s.GetQuery()})
+		case option.stmtCache != nil:
+			return s.execCached(ctx, db, option.stmtCache, nil)
+		}
 
-	return s.exec(ctx, stmt)
+		stmt, err := db.PreparexContext(ctx, s.GetQuery())
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+
+		return s.exec(ctx, stmt)
+	})
 }
 
 // run Execute the statement with transaction within supplied
 // query and update destination if not nil.
-func (s *Statement) runTx(ctx context.Context, tx *sqlx.Tx) error {
-	stmt, err := tx.PreparexContext(ctx, s.GetQuery())
+func (s *Statement) runTx(ctx context.Context, db *sqlx.DB, tx *sqlx.Tx, option dbOption) error {
+	return runQueryHooks(ctx, option.hooks, s.queryEvent(true), func(ctx context.Context) (int64, error) {
+		switch {
+		case option.bypassPrepare:
+			return s.exec(ctx, unpreparedStmt{ext: tx, query: s.GetQuery()})
+		case option.stmtCache != nil:
+			return s.execCached(ctx, db, option.stmtCache, tx)
+		}
+
+		stmt, err := tx.PreparexContext(ctx, s.GetQuery())
+		if err != nil {
+			return 0, err
+		}
+		defer stmt.Close()
+
+		return s.exec(ctx, stmt)
+	})
+}
+
+// execCached Execute the statement with the cached prepared statement,
+// bound to tx when not nil. A stale statement is prepared again once,
+// except in a transaction because its connection is gone.
+func (s *Statement) execCached(ctx context.Context, db *sqlx.DB, cache *statementCache, tx *sqlx.Tx) (int64, error) {
+	rowsAffected, err := s.execCachedOnce(ctx, db, cache, tx)
+	if err == nil || !isStaleStatementErr(err) {
+		return rowsAffected, err
+	}
+
+	cache.remove(s.GetQuery())
+	if tx != nil {
+		return rowsAffected, err
+	}
+
+	return s.execCachedOnce(ctx, db, cache, nil)
+}
+
+func (s *Statement) execCachedOnce(ctx context.Context, db *sqlx.DB, cache *statementCache, tx *sqlx.Tx) (int64, error) {
+	cached, err := cache.acquire(ctx, db, s.GetQuery())
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer cache.release(cached)
+
+	if tx == nil {
+		return s.exec(ctx, cached.stmt)
+	}
+
+	stmt := tx.StmtxContext(ctx, cached.stmt)
+	defer stmt.Close()
 
 	return s.exec(ctx, stmt)
 }
@@ -160,10 +269,75 @@ func run(ctx context.Context, db *sqlx.DB, statements ...*Statement) error {
 	return nil
 }
 
+// runParallel run multiple statements concurrently without transaction.
+func runParallel(ctx context.Context, db *sqlx.DB, workers int, statements ...*Statement) error {
+	if workers <= 0 || workers > len(statements) {
+		workers = len(statements)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    = make(map[int]error)
+		indexes = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := statements[i].run(ctx, db)
+				if err == nil {
+					continue
+				}
+
+				mu.Lock()
+				// statements cancelled because of another failure are not reported
+				if len(errs) == 0 || !errors.Is(err, context.Canceled) {
+					errs[i] = err
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for dispatched < len(statements) {
+		select {
+		case indexes <- dispatched:
+			dispatched++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if len(errs) == 0 {
+		if dispatched == len(statements) {
+			return nil
+		}
+		// the parent context is done before any statement failed
+		return &ParallelError{Errors: map[int]error{dispatched: ctx.Err()}}
+	}
+
+	for i := range errs {
+		statements[i].sync()
+	}
+
+	return &ParallelError{Errors: errs}
+}
+
 // runTx run multiple statements in single transactions.
-func runTx(ctx context.Context, tx *sqlx.Tx, statements ...*Statement) error {
+func runTx(ctx context.Context, db *sqlx.DB, tx *sqlx.Tx, statements ...*Statement) error {
+	option := getDBOption(db)
 	for i, statement := range statements {
-		err := statement.runTx(ctx, tx)
+		err := statement.runTx(ctx, db, tx, option)
 		if err != nil {
 			for j := i; j < 0; j-- {
 				statements[j].sync()
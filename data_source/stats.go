@@ -0,0 +1,142 @@
+package data_source
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DB_HEALTH_DEFAULT_PING_TIMEOUT = 2 * time.Second
+	// DB_HEALTH_DEFAULT_SATURATION is the ratio of in use connections to
+	// MaxOpenConnections above which a warning is logged.
+	DB_HEALTH_DEFAULT_SATURATION = 0.8
+)
+
+// DBHealth is a health.Checker of a connection pool.
+type DBHealth struct {
+	db                  *sqlx.DB
+	pingTimeout         time.Duration
+	saturationThreshold float64
+}
+
+type DBHealthOption func(*DBHealth)
+
+func WithPingTimeout(pingTimeout time.Duration) DBHealthOption {
+	return func(h *DBHealth) {
+		h.pingTimeout = pingTimeout
+	}
+}
+
+// WithSaturationThreshold set the in use ratio of MaxOpenConnections
+// logged as warning, between 0 and 1.
+func WithSaturationThreshold(saturationThreshold float64) DBHealthOption {
+	return func(h *DBHealth) {
+		h.saturationThreshold = saturationThreshold
+	}
+}
+
+func NewDBHealth(db *sqlx.DB, opts ...DBHealthOption) *DBHealth {
+	dbHealth := &DBHealth{
+		db:                  db,
+		pingTimeout:         DB_HEALTH_DEFAULT_PING_TIMEOUT,
+		saturationThreshold: DB_HEALTH_DEFAULT_SATURATION,
+	}
+
+	for _, opt := range opts {
+		opt(dbHealth)
+	}
+
+	return dbHealth
+}
+
+// Health ping the database within the ping timeout and log a warning when
+// the pool is saturated, saturation alone doesn't fail the check.
+func (h *DBHealth) Health(ctx context.Context) error {
+	const logCtx = "common.data_source.DBHealth.Health"
+
+	ctx, cancel := context.WithTimeout(ctx, h.pingTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		logger.Error(ctx, "failed health check database", err, logger.Tag{Key: "logCtx", Value: logCtx})
+		return err
+	}
+
+	warnDBSaturation(ctx, h.db.Stats(), h.saturationThreshold, logCtx)
+
+	return nil
+}
+
+// StartDBStatsLogger log the pool stats of db every interval until ctx is
+// done, with a warning when requests waited for a connection since the
+// previous log or the pool is saturated.
+func StartDBStatsLogger(ctx context.Context, db *sqlx.DB, interval time.Duration, opts ...DBHealthOption) {
+	const logCtx = "common.data_source.StartDBStatsLogger"
+
+	dbHealth := NewDBHealth(db, opts...)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var previous sql.DBStats
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			stats := db.Stats()
+			logger.Info(ctx, "db stats", dbStatsTags(stats, logCtx)...)
+
+			if stats.WaitCount > previous.WaitCount {
+				logger.Warn(ctx, "db connection wait",
+					logger.Tag{Key: "logCtx", Value: logCtx},
+					logger.Tag{Key: "waitCount", Value: stats.WaitCount - previous.WaitCount},
+					logger.Tag{Key: "waitDuration", Value: (stats.WaitDuration - previous.WaitDuration).String()},
+				)
+			}
+			warnDBSaturation(ctx, stats, dbHealth.saturationThreshold, logCtx)
+
+			previous = stats
+		}
+	}()
+}
+
+// IsDBSaturated report whether the in use connections reach threshold of
+// MaxOpenConnections, always false when MaxOpenConnections is unlimited.
+func IsDBSaturated(stats sql.DBStats, threshold float64) bool {
+	if stats.MaxOpenConnections <= 0 {
+		return false
+	}
+
+	return float64(stats.InUse)/float64(stats.MaxOpenConnections) >= threshold
+}
+
+func warnDBSaturation(ctx context.Context, stats sql.DBStats, threshold float64, logCtx string) {
+	if !IsDBSaturated(stats, threshold) {
+		return
+	}
+
+	logger.Warn(ctx, "db connection pool saturated", dbStatsTags(stats, logCtx)...)
+}
+
+func dbStatsTags(stats sql.DBStats, logCtx string) []logger.Tag {
+	return []logger.Tag{
+		{Key: "logCtx", Value: logCtx},
+		{Key: "maxOpenConnections", Value: stats.MaxOpenConnections},
+		{Key: "openConnections", Value: stats.OpenConnections},
+		{Key: "inUse", Value: stats.InUse},
+		{Key: "idle", Value: stats.Idle},
+		{Key: "waitCount", Value: stats.WaitCount},
+		{Key: "waitDuration", Value: stats.WaitDuration.String()},
+		{Key: "maxIdleClosed", Value: stats.MaxIdleClosed},
+		{Key: "maxIdleTimeClosed", Value: stats.MaxIdleTimeClosed},
+		{Key: "maxLifetimeClosed", Value: stats.MaxLifetimeClosed},
+	}
+}
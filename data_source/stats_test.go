@@ -0,0 +1,64 @@
+package data_source_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DBHealth(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			pingErr error
+		}
+		want struct {
+			err error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	errPing := errors.New("connection refused")
+	testCases := []testCase{
+		{
+			name: "Ping succeed",
+			args: args{pingErr: nil},
+			want: want{err: nil},
+		},
+		{
+			name: "Ping failed",
+			args: args{pingErr: errPing},
+			want: want{err: errPing},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbmock, queryMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			assert.Nil(t, err)
+			queryMock.ExpectPing().WillReturnError(testCase.args.pingErr)
+
+			err = commonDataSource.NewDBHealth(sqlx.NewDb(dbmock, "sqlmock")).Health(context.Background())
+
+			assert.Equal(t, testCase.want.err, err)
+			assert.Nil(t, queryMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_IsDBSaturated(t *testing.T) {
+	t.Parallel()
+	assert.False(t, commonDataSource.IsDBSaturated(sql.DBStats{MaxOpenConnections: 0, InUse: 100}, 0.8))
+	assert.False(t, commonDataSource.IsDBSaturated(sql.DBStats{MaxOpenConnections: 10, InUse: 7}, 0.8))
+	assert.True(t, commonDataSource.IsDBSaturated(sql.DBStats{MaxOpenConnections: 10, InUse: 8}, 0.8))
+}
@@ -0,0 +1,104 @@
+package data_source
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const EACH_BATCH_DEFAULT_SIZE = 1000
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Each run the statement without transaction and scan the result row by
+// row into T, so the whole result is never held in memory. The destination
+// of the statement is ignored. Iteration stop at the first error returned
+// by fn.
+func Each[T any](ctx context.Context, db *sqlx.DB, statement *Statement, fn func(T) error) error {
+	event := statement.queryEvent(false)
+	event.Operation = QueryEach
+
+	return runQueryHooks(ctx, getQueryHooks(db), event, func(ctx context.Context) (int64, error) {
+		if statement.enableDebug {
+			statement.log(ctx)
+		}
+
+		rows, err := db.QueryxContext(ctx, statement.GetQuery(), statement.GetArgs()...)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		var scanned int64
+		for rows.Next() {
+			var row T
+			if err = scanRow(rows, &row); err != nil {
+				return scanned, err
+			}
+			scanned++
+
+			if err = fn(row); err != nil {
+				return scanned, err
+			}
+		}
+
+		return scanned, rows.Err()
+	})
+}
+
+// EachBatch is Each calling fn with at most size rows at a time, size <= 0
+// means EACH_BATCH_DEFAULT_SIZE. The batch slice is reused between calls,
+// copy it if you keep it after fn return.
+func EachBatch[T any](ctx context.Context, db *sqlx.DB, statement *Statement, size int, fn func([]T) error) error {
+	if size <= 0 {
+		size = EACH_BATCH_DEFAULT_SIZE
+	}
+
+	batch := make([]T, 0, size)
+	err := Each(ctx, db, statement, func(row T) error {
+		batch = append(batch, row)
+		if len(batch) < size {
+			return nil
+		}
+
+		err := fn(batch)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+
+	return nil
+}
+
+// scanRow scan struct by the db tags and other types, including sql.Scanner
+// implementations and structs without exported fields like time.Time, as a
+// single column.
+func scanRow[T any](rows *sqlx.Rows, dest *T) error {
+	if isScannable(reflect.TypeOf(dest).Elem()) {
+		return rows.Scan(dest)
+	}
+
+	return rows.StructScan(dest)
+}
+
+func isScannable(rt reflect.Type) bool {
+	if rt.Kind() != reflect.Struct || reflect.PointerTo(rt).Implements(scannerType) {
+		return true
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		if rt.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package data_source_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamUser struct {
+	Id   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func Test_Each(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	query := "SELECT id, name FROM users WHERE status = ?"
+	queryMock.ExpectQuery(query).WithArgs("active").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John").AddRow(2, "Jane"))
+
+	users := make([]streamUser, 0)
+	err := commonDataSource.Each(context.Background(), dbmock,
+		commonDataSource.NewStatement(nil, query, "active"),
+		func(user streamUser) error {
+			users = append(users, user)
+			return nil
+		},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []streamUser{{Id: 1, Name: "John"}, {Id: 2, Name: "Jane"}}, users)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_EachStopOnError(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	query := "SELECT id FROM users"
+	queryMock.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+
+	errStop := errors.New("stop")
+	ids := make([]int, 0)
+	err := commonDataSource.Each(context.Background(), dbmock,
+		commonDataSource.NewStatement(nil, query),
+		func(id int) error {
+			ids = append(ids, id)
+			if id == 2 {
+				return errStop
+			}
+			return nil
+		},
+	)
+
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func Test_EachBatch(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	query := "SELECT id FROM users"
+	queryMock.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3).AddRow(4).AddRow(5))
+
+	batches := make([][]int, 0)
+	err := commonDataSource.EachBatch(context.Background(), dbmock,
+		commonDataSource.NewStatement(nil, query), 2,
+		func(ids []int) error {
+			batches = append(batches, append([]int{}, ids...))
+			return nil
+		},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
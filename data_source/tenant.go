@@ -0,0 +1,98 @@
+package data_source
+
+import (
+	"context"
+
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	TENANT_DEFAULT_COLUMN = "tenant_id"
+)
+
+// TenantScope scope the squirrel builders to the tenant carried by the
+// context, see context.WithTenant. Without tenant the builders return
+// errors.ErrMissingTenant, unless the context come from
+// context.WithoutTenantScope.
+type TenantScope struct {
+	column string
+}
+
+type TenantScopeOption func(*TenantScope)
+
+// WithTenantColumn set the tenant column, default tenant_id. Qualify it
+// with the table alias for joins, e.g. la.dealer_id.
+func WithTenantColumn(column string) TenantScopeOption {
+	return func(s *TenantScope) {
+		s.column = column
+	}
+}
+
+func NewTenantScope(opts ...TenantScopeOption) *TenantScope {
+	scope := &TenantScope{column: TENANT_DEFAULT_COLUMN}
+	for _, opt := range opts {
+		opt(scope)
+	}
+
+	return scope
+}
+
+// tenant return the tenant of ctx, skip is true when the query must not be
+// scoped.
+func (s *TenantScope) tenant(ctx context.Context) (tenantID any, skip bool, err error) {
+	if commonContext.IsTenantScopeSkipped(ctx) {
+		return nil, true, nil
+	}
+
+	tenantID, ok := commonContext.GetTenant(ctx)
+	if !ok {
+		return nil, false, commonErrors.ErrMissingTenant
+	}
+
+	return tenantID, false, nil
+}
+
+// Select add tenant_id = ? to the where clause.
+func (s *TenantScope) Select(ctx context.Context, query sq.SelectBuilder) (sq.SelectBuilder, error) {
+	tenantID, skip, err := s.tenant(ctx)
+	if err != nil || skip {
+		return query, err
+	}
+
+	return query.Where(sq.Eq{s.column: tenantID}), nil
+}
+
+// Update add tenant_id = ? to the where clause.
+func (s *TenantScope) Update(ctx context.Context, query sq.UpdateBuilder) (sq.UpdateBuilder, error) {
+	tenantID, skip, err := s.tenant(ctx)
+	if err != nil || skip {
+		return query, err
+	}
+
+	return query.Where(sq.Eq{s.column: tenantID}), nil
+}
+
+// Delete add tenant_id = ? to the where clause.
+func (s *TenantScope) Delete(ctx context.Context, query sq.DeleteBuilder) (sq.DeleteBuilder, error) {
+	tenantID, skip, err := s.tenant(ctx)
+	if err != nil || skip {
+		return query, err
+	}
+
+	return query.Where(sq.Eq{s.column: tenantID}), nil
+}
+
+// Insert set tenant_id of every inserted row, replacing the value when the
+// column is already inserted. It must be called after Columns/Values or
+// SetMap, INSERT ... SELECT isn't supported.
+func (s *TenantScope) Insert(ctx context.Context, query sq.InsertBuilder) (sq.InsertBuilder, error) {
+	tenantID, skip, err := s.tenant(ctx)
+	if err != nil || skip {
+		return query, err
+	}
+
+	return setInsertColumn(query, s.column, tenantID), nil
+}
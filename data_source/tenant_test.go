@@ -0,0 +1,129 @@
+package data_source_test
+
+import (
+	"context"
+	"testing"
+
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TenantScope(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			ctx   context.Context
+			build func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error)
+		}
+		want struct {
+			query string
+			args  []any
+			err   error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	scope := commonDataSource.NewTenantScope()
+	tenantCtx := commonContext.WithTenant(context.Background(), int64(12))
+	selectBuilder := func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+		return scope.Select(ctx, sq.Select("id").From("loans").Where(sq.Eq{"status": "approved"}))
+	}
+
+	testCases := []testCase{
+		{
+			name: "Select",
+			args: args{ctx: tenantCtx, build: selectBuilder},
+			want: want{
+				query: "SELECT id FROM loans WHERE status = ? AND tenant_id = ?",
+				args:  []any{"approved", int64(12)},
+			},
+		},
+		{
+			name: "Update",
+			args: args{ctx: tenantCtx, build: func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+				return scope.Update(ctx, sq.Update("loans").Set("status", "rejected").Where(sq.Eq{"id": 1}))
+			}},
+			want: want{
+				query: "UPDATE loans SET status = ? WHERE id = ? AND tenant_id = ?",
+				args:  []any{"rejected", 1, int64(12)},
+			},
+		},
+		{
+			name: "Delete",
+			args: args{ctx: tenantCtx, build: func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+				return scope.Delete(ctx, sq.Delete("loans").Where(sq.Eq{"id": 1}))
+			}},
+			want: want{
+				query: "DELETE FROM loans WHERE id = ? AND tenant_id = ?",
+				args:  []any{1, int64(12)},
+			},
+		},
+		{
+			name: "Insert",
+			args: args{ctx: tenantCtx, build: func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+				return scope.Insert(ctx, sq.Insert("loans").Columns("id", "status").Values(1, "new").Values(2, "new"))
+			}},
+			want: want{
+				query: "INSERT INTO loans (id,status,tenant_id) VALUES (?,?,?),(?,?,?)",
+				args:  []any{1, "new", int64(12), 2, "new", int64(12)},
+			},
+		},
+		{
+			name: "InsertReplaceTenant",
+			args: args{ctx: tenantCtx, build: func(ctx context.Context, scope *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+				return scope.Insert(ctx, sq.Insert("loans").Columns("tenant_id", "status").Values(99, "new"))
+			}},
+			want: want{
+				query: "INSERT INTO loans (tenant_id,status) VALUES (?,?)",
+				args:  []any{int64(12), "new"},
+			},
+		},
+		{
+			name: "MissingTenant",
+			args: args{ctx: context.Background(), build: selectBuilder},
+			want: want{err: commonErrors.ErrMissingTenant},
+		},
+		{
+			name: "WithoutTenantScope",
+			args: args{ctx: commonContext.WithoutTenantScope(context.Background()), build: selectBuilder},
+			want: want{
+				query: "SELECT id FROM loans WHERE status = ?",
+				args:  []any{"approved"},
+			},
+		},
+		{
+			name: "TenantColumn",
+			args: args{ctx: tenantCtx, build: func(ctx context.Context, _ *commonDataSource.TenantScope) (sq.Sqlizer, error) {
+				return commonDataSource.NewTenantScope(commonDataSource.WithTenantColumn("l.dealer_id")).
+					Select(ctx, sq.Select("l.id").From("loans l"))
+			}},
+			want: want{
+				query: "SELECT l.id FROM loans l WHERE l.dealer_id = ?",
+				args:  []any{int64(12)},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			builder, err := testCase.args.build(testCase.args.ctx, scope)
+			assert.Equal(t, testCase.want.err, err)
+			if err != nil {
+				return
+			}
+
+			query, args, err := builder.ToSql()
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.want.query, query)
+			assert.Equal(t, testCase.want.args, args)
+		})
+	}
+}
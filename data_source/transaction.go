@@ -26,22 +26,27 @@ func NewTransactionRunner(db *sqlx.DB) *TransactionRunner {
 	}
 }
 
+// WithTx run txFunc in a transaction, the query hooks of DB see the
+// whole transaction as a single QueryTx event.
 func (t *TransactionRunner) WithTx(ctx context.Context, txFunc TxFunc, opts *sql.TxOptions) error {
-	tx, err := StartTx(ctx, t.DB, opts)
-	if err != nil {
-		return err
-	}
+	event := &QueryEvent{Operation: QueryTx, InTx: true}
+	return runQueryHooks(ctx, getQueryHooks(t.DB), event, func(ctx context.Context) (int64, error) {
+		tx, err := StartTx(ctx, t.DB, opts)
+		if err != nil {
+			return 0, err
+		}
 
-	err = txFunc(tx)
-	if err != nil {
-		errRb := RollbackTx(tx)
-		if errRb != nil {
-			return errRb
+		err = txFunc(tx)
+		if err != nil {
+			errRb := RollbackTx(tx)
+			if errRb != nil {
+				return 0, errRb
+			}
+			return 0, err
 		}
-		return err
-	}
 
-	return CommitTx(tx)
+		return 0, CommitTx(tx)
+	})
 }
 
 func StartTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions) (*sqlx.Tx, error) {
@@ -0,0 +1,120 @@
+package data_source
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// Update update the row of table identified by the pk columns of data, a
+// pointer to a struct, and return the rows affected. The pk columns are
+// tagged with the pk option, by default the id column.
+//
+// When data has a version column, tagged with the version option or named
+// version, the update is optimistic locked:
+//
+//	UPDATE table SET ..., version = version + 1 WHERE id = ? AND version = ?
+//
+// the version of data is incremented on success and
+// errors.ErrStaleObject is returned when no row is affected.
+func Update(ctx context.Context, db sqlx.ExtContext, table string, data any, excluded ...string) (int64, error) {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return 0, fmt.Errorf("%w: update require a pointer to struct, got %T", commonErrors.ErrSQLQueryBuilder, data)
+	}
+	rv = rv.Elem()
+
+	pkFields, versionField, setFields := updateFields(structDbFields(rv.Type(), excluded...))
+	if len(pkFields) == 0 {
+		return 0, fmt.Errorf("%w: no pk column found in %T", commonErrors.ErrSQLQueryBuilder, data)
+	}
+
+	builder := sq.Update(table)
+	if isPostgresDriver(db.DriverName()) {
+		builder = builder.PlaceholderFormat(sq.Dollar)
+	}
+	for _, field := range setFields {
+		builder = builder.Set(field.Column, rv.FieldByIndex(field.Index).Interface())
+	}
+	for _, field := range pkFields {
+		builder = builder.Where(sq.Eq{field.Column: rv.FieldByIndex(field.Index).Interface()})
+	}
+
+	var version reflect.Value
+	if versionField != nil {
+		version = rv.FieldByIndex(versionField.Index)
+		if !isVersionKind(version.Kind()) {
+			return 0, fmt.Errorf("%w: version column %s must be an integer", commonErrors.ErrSQLQueryBuilder, versionField.Column)
+		}
+		builder = builder.
+			Set(versionField.Column, sq.Expr(versionField.Column+" + 1")).
+			Where(sq.Eq{versionField.Column: version.Interface()})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", commonErrors.ErrSQLQueryBuilder, err)
+	}
+
+	rowsAffected, err := execWithHooks(ctx, db, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", commonErrors.ErrSQLExec, err)
+	}
+
+	if versionField == nil {
+		return rowsAffected, nil
+	}
+	if rowsAffected == 0 {
+		return 0, commonErrors.ErrStaleObject
+	}
+
+	switch version.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		version.SetInt(version.Int() + 1)
+	default:
+		version.SetUint(version.Uint() + 1)
+	}
+
+	return rowsAffected, nil
+}
+
+// updateFields split fields into the pk, version and updated fields.
+func updateFields(fields []dbField) (pkFields []dbField, versionField *dbField, setFields []dbField) {
+	for i := range fields {
+		switch {
+		case fields[i].hasOption(TagOptionPk):
+			pkFields = append(pkFields, fields[i])
+		case fields[i].hasOption(TagOptionVersion):
+			versionField = &fields[i]
+		}
+	}
+
+	for i := range fields {
+		switch {
+		case fields[i].hasOption(TagOptionPk), fields[i].hasOption(TagOptionVersion):
+		case len(pkFields) == 0 && fields[i].Column == "id":
+			pkFields = append(pkFields, fields[i])
+		case versionField == nil && fields[i].Column == "version":
+			versionField = &fields[i]
+		default:
+			setFields = append(setFields, fields[i])
+		}
+	}
+
+	return pkFields, versionField, setFields
+}
+
+func isVersionKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,154 @@
+package data_source_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type loanApplication struct {
+	Id      int    `db:"id"`
+	Status  string `db:"status"`
+	Version int64  `db:"version"`
+}
+
+type loanApplicationTagged struct {
+	LoanId   string `db:"loan_id,pk"`
+	Status   string `db:"status"`
+	Revision uint   `db:"revision,version"`
+}
+
+type loanApplicationNoVersion struct {
+	Id     int    `db:"id"`
+	Status string `db:"status"`
+}
+
+func Test_Update(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			driverName   string
+			data         any
+			rowsAffected int64
+		}
+		want struct {
+			query        string
+			args         []driver.Value
+			rowsAffected int64
+			data         any
+			err          error
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Postgres",
+			args: args{
+				driverName:   "postgres",
+				data:         &loanApplication{Id: 1, Status: "approved", Version: 3},
+				rowsAffected: 1,
+			},
+			want: want{
+				query:        "UPDATE loan_applications SET status = $1, version = version + 1 WHERE id = $2 AND version = $3",
+				args:         []driver.Value{"approved", 1, int64(3)},
+				rowsAffected: 1,
+				data:         &loanApplication{Id: 1, Status: "approved", Version: 4},
+			},
+		},
+		{
+			name: "Stale",
+			args: args{
+				driverName: "postgres",
+				data:       &loanApplication{Id: 1, Status: "approved", Version: 3},
+			},
+			want: want{
+				query: "UPDATE loan_applications SET status = $1, version = version + 1 WHERE id = $2 AND version = $3",
+				args:  []driver.Value{"approved", 1, int64(3)},
+				data:  &loanApplication{Id: 1, Status: "approved", Version: 3},
+				err:   commonErrors.ErrStaleObject,
+			},
+		},
+		{
+			name: "MysqlTagOption",
+			args: args{
+				driverName:   "mysql",
+				data:         &loanApplicationTagged{LoanId: "L-1", Status: "rejected", Revision: 7},
+				rowsAffected: 1,
+			},
+			want: want{
+				query:        "UPDATE loan_applications SET status = ?, revision = revision + 1 WHERE loan_id = ? AND revision = ?",
+				args:         []driver.Value{"rejected", "L-1", uint(7)},
+				rowsAffected: 1,
+				data:         &loanApplicationTagged{LoanId: "L-1", Status: "rejected", Revision: 8},
+			},
+		},
+		{
+			name: "WithoutVersion",
+			args: args{
+				driverName: "postgres",
+				data:       &loanApplicationNoVersion{Id: 1, Status: "approved"},
+			},
+			want: want{
+				query: "UPDATE loan_applications SET status = $1 WHERE id = $2",
+				args:  []driver.Value{"approved", 1},
+				data:  &loanApplicationNoVersion{Id: 1, Status: "approved"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			db, queryMock := mockSqlxDriver(testCase.args.driverName)
+
+			queryMock.ExpectExec(testCase.want.query).
+				WithArgs(testCase.want.args...).
+				WillReturnResult(sqlmock.NewResult(0, testCase.args.rowsAffected))
+
+			rowsAffected, err := commonDataSource.Update(context.Background(), db, "loan_applications", testCase.args.data)
+			assert.Equal(t, testCase.want.err, err)
+			assert.Equal(t, testCase.want.rowsAffected, rowsAffected)
+			assert.Equal(t, testCase.want.data, testCase.args.data)
+			assert.Nil(t, queryMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_UpdateInvalidData(t *testing.T) {
+	t.Parallel()
+	db, _ := mockSqlxDriver("postgres")
+
+	_, err := commonDataSource.Update(context.Background(), db, "loan_applications", loanApplication{Id: 1})
+	assert.ErrorIs(t, err, commonErrors.ErrSQLQueryBuilder)
+
+	_, err = commonDataSource.Update(context.Background(), db, "loan_applications", &struct {
+		Status string `db:"status"`
+	}{})
+	assert.ErrorIs(t, err, commonErrors.ErrSQLQueryBuilder)
+}
+
+func Test_UpdateKeepDriverError(t *testing.T) {
+	t.Parallel()
+	db, queryMock := mockSqlxDriver("postgres")
+	queryMock.ExpectExec("UPDATE loan_applications SET status = $1, version = version + 1 WHERE id = $2 AND version = $3").
+		WithArgs("approved", 1, int64(3)).
+		WillReturnError(&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"})
+
+	_, err := commonDataSource.Update(context.Background(), db, "loan_applications",
+		&loanApplication{Id: 1, Status: "approved", Version: 3})
+
+	var pqErr *pq.Error
+	assert.ErrorIs(t, err, commonErrors.ErrSQLExec)
+	assert.ErrorAs(t, err, &pqErr)
+	assert.Equal(t, pq.ErrorCode("23505"), pqErr.Code)
+}
@@ -15,6 +15,19 @@ func Exec(ctx context.Context, db *sqlx.DB, statements ...*Statement) error {
 	return nil
 }
 
+// ExecParallel run independent read statements concurrently without
+// transaction using at most workers connections, workers <= 0 means one
+// worker per statement. The remaining statements are cancelled on the first
+// error and the failures are returned as *ParallelError.
+func ExecParallel(ctx context.Context, db *sqlx.DB, workers int, statements ...*Statement) error {
+	err := runParallel(ctx, db, workers, statements...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ExecTx wrapping multiple queries or single query in a transaction.
 func ExecTx(ctx context.Context, db *sqlx.DB, statements ...*Statement) error {
 	tx, err := db.BeginTxx(ctx, nil)
@@ -22,7 +35,7 @@ func ExecTx(ctx context.Context, db *sqlx.DB, statements ...*Statement) error {
 		return err
 	}
 
-	if err = runTx(ctx, tx, statements...); err != nil {
+	if err = runTx(ctx, db, tx, statements...); err != nil {
 		if er := tx.Rollback(); er != nil {
 			return er
 		}
@@ -2,6 +2,7 @@ package data_source_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	commonDataSource "bitbucket.org/moladinTech/go-lib-common/data_source"
@@ -218,3 +219,46 @@ func mockSqlx() (mockSqlx *sqlx.DB, queryMock sqlmock.Sqlmock) {
 	mockSqlx = sqlx.NewDb(dbmock, "sqlmock")
 	return mockSqlx, queryMock
 }
+
+func Test_ExecParallel(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	queryMock.MatchExpectationsInOrder(false)
+	usersQuery := "SELECT id FROM users"
+	rolesQuery := "SELECT id FROM roles"
+	queryMock.ExpectPrepare(usersQuery).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	queryMock.ExpectPrepare(rolesQuery).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	var users, roles []int
+	err := commonDataSource.ExecParallel(context.Background(), dbmock, 2,
+		commonDataSource.NewStatement(&users, usersQuery),
+		commonDataSource.NewStatement(&roles, rolesQuery),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, users)
+	assert.Equal(t, []int{3}, roles)
+	assert.Nil(t, queryMock.ExpectationsWereMet())
+}
+
+func Test_ExecParallelError(t *testing.T) {
+	t.Parallel()
+	dbmock, queryMock := mockSqlx()
+	query := "SELECT id FROM users"
+	errQuery := errors.New("connection reset")
+	queryMock.ExpectPrepare(query).ExpectQuery().WillReturnError(errQuery)
+
+	var users []int
+	err := commonDataSource.ExecParallel(context.Background(), dbmock, 1,
+		commonDataSource.NewStatement(&users, query),
+		commonDataSource.NewStatement(&users, "SELECT id FROM roles"),
+	)
+
+	var parallelErr *commonDataSource.ParallelError
+	assert.ErrorAs(t, err, &parallelErr)
+	assert.ErrorIs(t, err, errQuery)
+	assert.Equal(t, map[int]error{0: errQuery}, parallelErr.Errors)
+	assert.Equal(t, "stmt[0]: connection reset", err.Error())
+}
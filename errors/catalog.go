@@ -0,0 +1,188 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+)
+
+// Error is a typed error identified by a stable machine readable code,
+// sent as the code of the response. It's immutable, WithData return a
+// copy so a catalog error can be returned by concurrent requests.
+type Error struct {
+	code       string
+	statusCode int
+	message    string
+	messageKey string
+	data       any
+}
+
+type ErrorOption func(*Error)
+
+// WithMessageKey set the key of the localized message, default the code.
+func WithMessageKey(messageKey string) ErrorOption {
+	return func(e *Error) {
+		e.messageKey = messageKey
+	}
+}
+
+// New return a typed error, register it with Register to make it part of
+// the catalog.
+//
+//	ErrLoanNotFound = errors.New("LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+func New(code string, statusCode int, message string, opts ...ErrorOption) *Error {
+	e := &Error{
+		code:       code,
+		statusCode: statusCode,
+		message:    message,
+		messageKey: code,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func (e *Error) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *Error) Message() string {
+	return e.message
+}
+
+func (e *Error) MessageKey() string {
+	return e.messageKey
+}
+
+func (e *Error) Data() any {
+	return e.data
+}
+
+// WithData return a copy of e with the data of the response.
+func (e *Error) WithData(data any) *Error {
+	copied := *e
+	copied.data = data
+
+	return &copied
+}
+
+// Is match the errors having the same code, so the copies returned by
+// WithData match the catalog error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.code == e.code
+}
+
+// Response return the http response of e.
+func (e *Error) Response() Response {
+	return Response{
+		StatusCode: e.statusCode,
+		Response: responseModel.Response{
+			Status:  responseModel.StatusFail,
+			Message: e.message,
+			Code:    e.code,
+			Data:    e.data,
+		},
+		MessageKey: e.messageKey,
+	}
+}
+
+var catalog = struct {
+	mu     sync.RWMutex
+	errors map[string]*Error
+}{errors: make(map[string]*Error)}
+
+// Register add the errors to the catalog, it panics when a code is already
+// registered so the codes stay unique. Call it from init or main.
+func Register(errs ...*Error) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	for _, e := range errs {
+		if _, ok := catalog.errors[e.code]; ok {
+			panic(fmt.Sprintf("errors: code %s is already registered", e.code))
+		}
+	}
+	for _, e := range errs {
+		catalog.errors[e.code] = e
+	}
+}
+
+// Lookup return the registered error of code.
+func Lookup(code string) (*Error, bool) {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	e, ok := catalog.errors[code]
+
+	return e, ok
+}
+
+// Catalog return the registered errors sorted by code, e.g. to document
+// them.
+func Catalog() []*Error {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	errs := make([]*Error, 0, len(catalog.errors))
+	for _, e := range catalog.errors {
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].code < errs[j].code
+	})
+
+	return errs
+}
+
+// GetResponse return the response of err: the response of its key error
+// (WithResponse, then typed error), then MapErrorResponse, then the first
+// WithResponse or typed error in its chain.
+func GetResponse(err error) (Response, bool) {
+	key := GetErrKey(err)
+	if joined, ok := key.(interface{ Unwrap() []error }); ok {
+		return joinedResponse(joined.Unwrap())
+	}
+
+	if response, ok := responseOf(key); ok {
+		return response, true
+	}
+
+	if key == ErrCustom && ErrCustomResponse.StatusCode != 0 {
+		return ErrCustomResponse, true
+	}
+
+	if response, ok := MapErrorResponse[key]; ok {
+		return response, true
+	}
+
+	return responseOf(err)
+}
+
+func responseOf(err error) (Response, bool) {
+	var withResponse *responseError
+	if stderrors.As(err, &withResponse) {
+		return withResponse.response, true
+	}
+
+	var typed *Error
+	if stderrors.As(err, &typed) {
+		return typed.Response(), true
+	}
+
+	return Response{}, false
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// useEmptyCatalog replace the global catalog with an empty one for the
+// duration of t, so the test can be repeated with -count.
+func useEmptyCatalog(t *testing.T) {
+	catalog.mu.Lock()
+	registered := catalog.errors
+	catalog.errors = make(map[string]*Error)
+	catalog.mu.Unlock()
+
+	t.Cleanup(func() {
+		catalog.mu.Lock()
+		catalog.errors = registered
+		catalog.mu.Unlock()
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	useEmptyCatalog(t)
+
+	errLoanNotFound := New("TEST_LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+	errLoanClosed := New("TEST_LOAN_CLOSED", http.StatusUnprocessableEntity, "Loan Is Closed", WithMessageKey("loan.closed"))
+	Register(errLoanNotFound, errLoanClosed)
+
+	assert.Panics(t, func() {
+		Register(New("TEST_LOAN_NOT_FOUND", http.StatusBadRequest, "duplicate"))
+	})
+
+	found, ok := Lookup("TEST_LOAN_CLOSED")
+	assert.True(t, ok)
+	assert.Equal(t, errLoanClosed, found)
+	assert.Equal(t, "loan.closed", found.MessageKey())
+	assert.Equal(t, "TEST_LOAN_NOT_FOUND", errLoanNotFound.MessageKey())
+
+	codes := make([]string, 0)
+	for _, e := range Catalog() {
+		codes = append(codes, e.Code())
+	}
+	assert.Equal(t, []string{"TEST_LOAN_CLOSED", "TEST_LOAN_NOT_FOUND"}, codes)
+}
+
+func TestGetResponse(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			response Response
+			ok       bool
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	errLoanNotFound := New("TEST_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+	notFoundResponse := Response{
+		StatusCode: http.StatusNotFound,
+		Response: responseModel.Response{
+			Status:  responseModel.StatusFail,
+			Message: "Loan Not Found",
+			Code:    "TEST_NOT_FOUND",
+		},
+		MessageKey: "TEST_NOT_FOUND",
+	}
+	notFoundWithData := notFoundResponse
+	notFoundWithData.Response = responseModel.Response{
+		Status:  responseModel.StatusFail,
+		Message: "Loan Not Found",
+		Code:    "TEST_NOT_FOUND",
+		Data:    1,
+	}
+
+	testCases := []testCase{
+		{
+			name: "TypedError",
+			args: args{err: errLoanNotFound},
+			want: want{response: notFoundResponse, ok: true},
+		},
+		{
+			name: "TypedErrorWithData",
+			args: args{err: errLoanNotFound.WithData(1)},
+			want: want{response: notFoundWithData, ok: true},
+		},
+		{
+			name: "WrappedTypedError",
+			args: args{err: WrapWithErr(stderrors.New("no rows"), errLoanNotFound)},
+			want: want{response: notFoundResponse, ok: true},
+		},
+		{
+			name: "MapErrorResponse",
+			args: args{err: ErrInvalidPagination},
+			want: want{response: MapErrorResponse[ErrInvalidPagination], ok: true},
+		},
+		{
+			name: "Unknown",
+			args: args{err: stderrors.New("unknown")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			response, ok := GetResponse(testCase.args.err)
+			assert.Equal(t, testCase.want.ok, ok)
+			assert.Equal(t, testCase.want.response, response)
+		})
+	}
+
+	assert.True(t, stderrors.Is(errLoanNotFound.WithData(1), errLoanNotFound))
+	assert.Nil(t, errLoanNotFound.Data())
+}
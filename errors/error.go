@@ -4,38 +4,111 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
-	"bitbucket.org/moladinTech/go-lib-common/logger"
 	"bitbucket.org/moladinTech/go-lib-common/registry"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
+// stackDepth is the maximum number of frames captured by Wrap.
+const stackDepth = 32
+
 type err struct {
 	original      error
 	wrapped       error
-	stacktrace    string
 	keyerr        error
 	stack         []uintptr
+	fields        map[string]any
 	logCtx        string
 	isNotify      bool
 	isSuccessResp bool
 }
 
+// StackTrace return the program counters of the stack captured where the
+// error is wrapped first, it's extracted by sentry.
 func (e *err) StackTrace() []uintptr {
 	return e.stack
 }
 
+// Stack return the frames of StackTrace, it's used by logger.MarshalStack
+// and printed by %+v.
+func (e *err) Stack() pkgerrors.StackTrace {
+	stackTrace := make(pkgerrors.StackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		stackTrace[i] = pkgerrors.Frame(pc)
+	}
+
+	return stackTrace
+}
+
+// Fields return the fields of e and of the errors it wraps, the outer
+// fields win.
+func (e *err) Fields() map[string]any {
+	fields := make(map[string]any)
+	for _, inner := range []error{e.original, e.wrapped} {
+		if val, ok := inner.(*err); ok {
+			for key, value := range val.Fields() {
+				fields[key] = value
+			}
+		}
+	}
+	for key, value := range e.fields {
+		fields[key] = value
+	}
+
+	return fields
+}
+
+// Format print Error() for %s and %v, %+v add the fields and the stack
+// trace.
+func (e *err) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, e.Error())
+			e.writeFields(s)
+			_, _ = fmt.Fprintf(s, "%+v", e.Stack())
+			return
+		}
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// writeFields write the fields sorted by key as " key=value".
+func (e *err) writeFields(w io.Writer) {
+	fields := e.Fields()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(w, " %s=%+v", key, fields[key])
+	}
+}
+
+// WithNotify capture the error and send the notification now, unless the
+// notify policy decides otherwise, HttpErrResp and HttpResp won't send it
+// again.
 func (e *err) WithNotify(ctx context.Context, rgs registry.IRegistry) *err {
-	rgs.GetSentry().CaptureException(e)
-	// send notif
-	slackMessage := rgs.GetNotif().GetFormattedMessage(ctx, e.logCtx, e)
-	errSlack := rgs.GetNotif().Send(ctx, slackMessage)
-	if errSlack != nil {
-		logger.Error(ctx, "Error sending notif to slack", errSlack)
+	param := NotifyParam{Err: e, Force: true}
+	if response, ok := GetResponse(e); ok {
+		param.StatusCode = response.StatusCode
 	}
 
+	decision := GetNotifyPolicy().Decide(param)
+	dispatch(ctx, rgs, decision, e, e.logCtx)
+
 	e.isNotify = true
 	return e
 }
@@ -50,10 +123,10 @@ func (e *err) Error() string {
 	var wrapped string
 
 	if e.wrapped == nil {
-		return e.original.Error() + e.stacktrace
+		return e.original.Error()
 	}
 
-	wrapped = e.wrapped.Error() + e.stacktrace
+	wrapped = e.wrapped.Error()
 
 	if e.original != nil {
 		if _, ok := e.original.(*err); !ok {
@@ -98,30 +171,19 @@ func RootErr(err_ error) error {
 }
 
 func Wrap(err_ error) *err {
-	pc, file, no, _ := runtime.Caller(1)
-
-	// Current working directory
-	dir, _ := os.Getwd()
-	splitDir := strings.Split(dir, "/")
-	rootDir := splitDir[len(splitDir)-1]
-
-	logCtx := generateLogCtx(pc, file, rootDir)
+	pc, file, _, _ := runtime.Caller(1)
 
 	retErr := err{
-		original:   err_,
-		wrapped:    nil,
-		stacktrace: " -- At : " + fmt.Sprintf("%s:%d", file, no),
-		keyerr:     GetErrKey(err_),
-		stack:      []uintptr{pc},
-		logCtx:     logCtx,
+		original: err_,
+		wrapped:  nil,
+		keyerr:   GetErrKey(err_),
+		stack:    callers(),
+		logCtx:   logCtxOf(pc, file),
 	}
 
-	// since its error is in DFS, we want the message to be like bfs, to be bfs we implement when we call this func
+	// keep the stack of the first wrap, it already contains the callers
 	if val, ok := err_.(*err); ok {
-		val.stacktrace = retErr.stacktrace + val.stacktrace
-		retErr.stacktrace = ""
-
-		retErr.stack = append(retErr.stack, val.stack...)
+		retErr.stack = val.stack
 	}
 
 	return &retErr
@@ -130,23 +192,72 @@ func Wrap(err_ error) *err {
 
 // WrapWithErr wrap new error into an existing error
 func WrapWithErr(original error, wrapped error) *err {
-	pc, file, no, _ := runtime.Caller(1)
+	pc, file, _, _ := runtime.Caller(1)
+
+	retErr := err{
+		original: original,
+		wrapped:  wrapped,
+		keyerr:   GetErrKey(wrapped),
+		stack:    callers(),
+		logCtx:   logCtxOf(pc, file),
+	}
+
+	if val, ok := original.(*err); ok {
+		retErr.stack = val.stack
+	}
+
+	return &retErr
+}
+
+// With return err with the key value fields, logged by logger.Error and
+// printed by %+v. err is wrapped when it isn't wrapped yet.
+//
+//	return errors.With(err, "loan_id", loan.Id, "status", loan.Status)
+func With(err_ error, keyvals ...any) *err {
+	var retErr err
+	if val, ok := err_.(*err); ok {
+		retErr = *val
+	} else {
+		pc, file, _, _ := runtime.Caller(1)
+		retErr = err{
+			original: err_,
+			keyerr:   GetErrKey(err_),
+			stack:    callers(),
+			logCtx:   logCtxOf(pc, file),
+		}
+	}
 
+	fields := make(map[string]any, len(retErr.fields)+len(keyvals)/2)
+	for key, value := range retErr.fields {
+		fields[key] = value
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		var value any
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fields[fmt.Sprint(keyvals[i])] = value
+	}
+	retErr.fields = fields
+
+	return &retErr
+}
+
+// callers return the stack of the caller of Wrap, WrapWithErr or With.
+func callers() []uintptr {
+	pcs := make([]uintptr, stackDepth)
+	n := runtime.Callers(3, pcs)
+
+	return pcs[:n]
+}
+
+func logCtxOf(pc uintptr, file string) string {
 	// Current working directory
 	dir, _ := os.Getwd()
 	splitDir := strings.Split(dir, "/")
 	rootDir := splitDir[len(splitDir)-1]
 
-	logCtx := generateLogCtx(pc, file, rootDir)
-
-	return &err{
-		original:   original,
-		wrapped:    wrapped,
-		keyerr:     GetErrKey(wrapped),
-		stacktrace: " -- At : " + fmt.Sprintf("%s:%d", file, no),
-		stack:      []uintptr{pc},
-		logCtx:     logCtx,
-	}
+	return generateLogCtx(pc, file, rootDir)
 }
 
 // get error as key to compare what the output response will be
@@ -161,7 +272,10 @@ func GetErrKey(err_ error) error {
 // generateLogCtx will generate the log context
 func generateLogCtx(pc uintptr, file, rootDir string) string {
 	var filePath, funcName string
-	filePath = strings.Split(file, fmt.Sprintf("/%s/", rootDir))[1]
+	filePath = filepath.Base(file)
+	if paths := strings.SplitN(file, fmt.Sprintf("/%s/", rootDir), 2); len(paths) == 2 {
+		filePath = paths[1]
+	}
 	filePath = strings.Split(filePath, ".")[0]
 	filePath = strings.ReplaceAll(filePath, "/", ".")
 	funcName = runtime.FuncForPC(pc).Name()
@@ -176,6 +290,7 @@ func ErrorMatcher(passedError error) (matchedError *err, isErrorMatch bool) {
 	return matchedError, isErrorMatch
 }
 
+// Deprecated: use Report, the decision is made by the notify policy.
 type ParamIsSendNotif struct {
 	IsMapMatch   bool
 	ResponseMap  Response
@@ -183,6 +298,7 @@ type ParamIsSendNotif struct {
 	MatchedError *err
 }
 
+// Deprecated: use NotifyPolicy.Decide.
 func IsCaptureErrorAndSendNotif(param ParamIsSendNotif) (isMatch bool) {
 	if !param.IsMapMatch || (param.IsMapMatch && param.ResponseMap.StatusCode >= 500) &&
 		!param.IsErrorMatch || param.IsErrorMatch && !param.MatchedError.isNotify {
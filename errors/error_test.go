@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	stderrors "errors"
+	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 
+	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -21,7 +24,7 @@ type TestErrorSuite struct {
 func (t *TestErrorSuite) SetupSuite() {
 	t.path, _ = os.Getwd()
 	t.getPath = func(lineNum string) string {
-		return " -- At : " + t.path + "/error_test.go:" + lineNum
+		return "\t" + t.path + "/error_test.go:" + lineNum
 	}
 }
 
@@ -63,9 +66,10 @@ func (t *TestErrorSuite) TestWrappedError() {
 	err = WrapWithErr(err, testErr2)
 	err = WrapWithErr(err, testErr3)
 	err = WrapWithErr(err, testErr4)
-	expected := "test err 4" + t.getPath("65") + ": test err 3" + t.getPath("64") + ": test err 2" + t.getPath("63") + ": test err 1" + t.getPath("62") + ": root cause : origin"
+	expected := "test err 4: test err 3: test err 2: test err 1: root cause : origin"
 
 	assert.Equal(t.T(), expected, err.Error())
+	assert.Equal(t.T(), expected, fmt.Sprintf("%v", err))
 }
 
 func (t *TestErrorSuite) TestWrap() {
@@ -73,12 +77,27 @@ func (t *TestErrorSuite) TestWrap() {
 
 	err := Wrap(testErr1)
 	err = Wrap(err)
-	expected := "context deadline exceeded" + t.getPath("75") + t.getPath("74")
+	expected := "context deadline exceeded"
 
 	errKey := GetErrKey(err)
 
 	assert.Equal(t.T(), expected, err.Error())
 	assert.Equal(t.T(), testErr1, errKey)
+	assert.Contains(t.T(), fmt.Sprintf("%+v", err), t.getPath("78"))
+}
+
+func (t *TestErrorSuite) TestWith() {
+	err := With(context.DeadlineExceeded, "loan_id", 1, "status", "new")
+	err = With(Wrap(err), "status", "approved", "odd")
+
+	assert.Equal(t.T(), "context deadline exceeded", err.Error())
+	assert.Equal(t.T(), map[string]any{"loan_id": 1, "status": "approved", "odd": nil}, err.Fields())
+	assert.Equal(t.T(), context.DeadlineExceeded, GetErrKey(err))
+	assert.True(t.T(), stderrors.Is(err, context.DeadlineExceeded))
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t.T(), strings.HasPrefix(verbose, "context deadline exceeded loan_id=1 odd=<nil> status=approved\n"))
+	assert.Contains(t.T(), verbose, t.getPath("90"))
 }
 
 func (t *TestErrorSuite) TestRootErr() {
@@ -116,24 +135,21 @@ func (t *TestErrorSuite) TestGetStackTrace() {
 
 	errWrap1 := WrapWithErr(err1, err2)
 	errWrap2 := AnotherWrapFunc(t.T(), errWrap1)
-	var stackPtr []uintptr
+	var (
+		pcs        []uintptr
+		stackTrace pkgerrors.StackTrace
+	)
 	if err_, ok := errWrap2.(*err); ok {
-		stackPtr = err_.StackTrace()
-	}
-	frames := runtime.CallersFrames(stackPtr)
-	funcName := make([]string, 0)
-	for {
-		frame, ok := frames.Next()
-
-		funcName = append(funcName, frame.Function)
-
-		if !ok {
-			break
-		}
+		pcs = err_.StackTrace()
+		stackTrace = err_.Stack()
 	}
 
-	assert.Equal(t.T(), []string{"bitbucket.org/moladinTech/go-lib-common/errors.AnotherWrapFunc",
-		"bitbucket.org/moladinTech/go-lib-common/errors.(*TestErrorSuite).TestGetStackTrace"}, funcName)
+	// the stack of the first wrap is kept
+	assert.Equal(t.T(), "bitbucket.org/moladinTech/go-lib-common/errors.(*TestErrorSuite).TestGetStackTrace",
+		runtime.FuncForPC(uintptr(stackTrace[0])-1).Name())
+	assert.Greater(t.T(), len(stackTrace), 1)
+	assert.Len(t.T(), pcs, len(stackTrace))
+	assert.Equal(t.T(), pcs[0], uintptr(stackTrace[0]))
 }
 
 func AnotherWrapFunc(t *testing.T, err error) error {
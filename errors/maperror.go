@@ -8,19 +8,28 @@ import (
 )
 
 var (
-	ErrCustomResponse   Response
-	ErrSQLQueryBuilder  = errors.New("error query builder")
-	ErrSQLExec          = errors.New("error sql exec")
-	ErrRequiredMessage  = errors.New("require message to start money recon")
-	ErrMigrate          = errors.New("failed when migrating database")
-	ErrFailedParseToCSV = errors.New("failed when converting data to csv")
-	ErrFailedUploadToS3 = errors.New("failed when uploading file to s3")
-	ErrCustom           = errors.New("custom message")
+	ErrCustomResponse    Response
+	ErrSQLQueryBuilder   = errors.New("error query builder")
+	ErrSQLExec           = errors.New("error sql exec")
+	ErrRequiredMessage   = errors.New("require message to start money recon")
+	ErrMigrate           = errors.New("failed when migrating database")
+	ErrFailedParseToCSV  = errors.New("failed when converting data to csv")
+	ErrFailedUploadToS3  = errors.New("failed when uploading file to s3")
+	ErrCustom            = errors.New("custom message")
+	ErrInvalidPagination = errors.New("invalid pagination parameter")
+	ErrBulkInsert        = errors.New("failed when bulk inserting rows")
+	ErrStaleObject       = errors.New("data has been modified by another request")
+	ErrMissingTenant     = errors.New("tenant is required to scope the query")
+	ErrInvalidRequest    = errors.New("failed when binding the request")
+	ErrValidation        = errors.New("request validation failed")
 )
 
 type Response struct {
 	StatusCode int
 	Response   interface{}
+	// MessageKey is the key of the localized message, the code of the
+	// response when empty.
+	MessageKey string
 }
 
 var MapErrorResponse = map[error]Response{
@@ -28,6 +37,7 @@ var MapErrorResponse = map[error]Response{
 		StatusCode: http.StatusInternalServerError,
 		Response: responseModel.Response{
 			Message: http.StatusText(http.StatusInternalServerError),
+			Code:    "SQL_QUERY_BUILDER",
 			Status:  responseModel.StatusFail,
 		},
 	},
@@ -36,6 +46,7 @@ var MapErrorResponse = map[error]Response{
 		StatusCode: http.StatusInternalServerError,
 		Response: responseModel.Response{
 			Message: "Database Server Failed to Execute, Please Try Again",
+			Code:    "SQL_EXEC",
 			Status:  responseModel.StatusFail,
 		},
 	},
@@ -44,6 +55,7 @@ var MapErrorResponse = map[error]Response{
 		StatusCode: http.StatusBadRequest,
 		Response: responseModel.Response{
 			Message: "Message Required",
+			Code:    "MESSAGE_REQUIRED",
 			Status:  responseModel.StatusFail,
 		},
 	},
@@ -52,11 +64,68 @@ var MapErrorResponse = map[error]Response{
 		StatusCode: http.StatusInternalServerError,
 		Response: responseModel.Response{
 			Message: "Failed When Migrating The Database",
+			Code:    "MIGRATE",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrInvalidPagination: {
+		StatusCode: http.StatusBadRequest,
+		Response: responseModel.Response{
+			Message: "Invalid Page, Limit, Sort Or Filter Parameter",
+			Code:    "INVALID_PAGINATION",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrBulkInsert: {
+		StatusCode: http.StatusInternalServerError,
+		Response: responseModel.Response{
+			Message: "Failed When Inserting The Data",
+			Code:    "BULK_INSERT",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrStaleObject: {
+		StatusCode: http.StatusConflict,
+		Response: responseModel.Response{
+			Message: "Data Has Been Modified By Another Request, Please Reload And Try Again",
+			Code:    "STALE_OBJECT",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrMissingTenant: {
+		StatusCode: http.StatusForbidden,
+		Response: responseModel.Response{
+			Message: http.StatusText(http.StatusForbidden),
+			Code:    "MISSING_TENANT",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrInvalidRequest: {
+		StatusCode: http.StatusBadRequest,
+		Response: responseModel.Response{
+			Message: http.StatusText(http.StatusBadRequest),
+			Code:    "INVALID_REQUEST",
+			Status:  responseModel.StatusFail,
+		},
+	},
+
+	ErrValidation: {
+		StatusCode: http.StatusUnprocessableEntity,
+		Response: responseModel.Response{
+			Message: http.StatusText(http.StatusUnprocessableEntity),
+			Code:    "VALIDATION",
 			Status:  responseModel.StatusFail,
 		},
 	},
 }
 
+// Deprecated: the response is shared by every request, return
+// WithResponse(ErrCustom, statusCode, message, data) instead.
 func SetDataErrCustom(statusCode int, message string, data any) {
 	ErrCustomResponse = Response{
 		StatusCode: statusCode,
@@ -68,6 +137,8 @@ func SetDataErrCustom(statusCode int, message string, data any) {
 	}
 }
 
+// Deprecated: GetResponse read ErrCustomResponse for ErrCustom, return
+// WithResponse(ErrCustom, statusCode, message, data) instead.
 func SetErrCustomResponse() {
 	MapErrorResponse[ErrCustom] = ErrCustomResponse
 }
@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// multiErr aggregate several errors, e.g. the failed rows of a batch. The
+// children are kept as they are, so each keeps its own logCtx and stack.
+type multiErr struct {
+	errs []error
+}
+
+// Join return an error aggregating the non nil errs, nil when there is
+// none. errors.Is and errors.As match any of the errors, HttpErrResp
+// return the response with the highest status code.
+//
+//	var errs []error
+//	for i, row := range rows {
+//	    if err := insert(ctx, row); err != nil {
+//	        errs = append(errs, liberrors.With(err, "row", i))
+//	    }
+//	}
+//	return liberrors.Join(errs...)
+func Join(errs ...error) error {
+	joined := &multiErr{}
+	for _, err_ := range errs {
+		if err_ != nil {
+			joined.errs = append(joined.errs, err_)
+		}
+	}
+
+	if len(joined.errs) == 0 {
+		return nil
+	}
+
+	return joined
+}
+
+func (m *multiErr) Error() string {
+	messages := make([]string, 0, len(m.errs))
+	for _, err_ := range m.errs {
+		messages = append(messages, err_.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap return the errors, used by errors.Is and errors.As of Go 1.20.
+func (m *multiErr) Unwrap() []error {
+	return m.errs
+}
+
+// GetLogCtx return the logCtx of the errors having one.
+func (m *multiErr) GetLogCtx() string {
+	logCtxs := make([]string, 0, len(m.errs))
+	for _, err_ := range m.errs {
+		if logCtx := logCtxOfErr(err_); logCtx != "" {
+			logCtxs = append(logCtxs, logCtx)
+		}
+	}
+
+	return strings.Join(logCtxs, ", ")
+}
+
+// Format print every error on its own line, %+v print each of them with
+// its logCtx, fields and stack.
+func (m *multiErr) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = fmt.Fprintf(s, "%d errors occurred:", len(m.errs))
+			for i, err_ := range m.errs {
+				_, _ = fmt.Fprintf(s, "\n[%d]", i+1)
+				if logCtx := logCtxOfErr(err_); logCtx != "" {
+					_, _ = fmt.Fprintf(s, " %s:", logCtx)
+				}
+				_, _ = fmt.Fprintf(s, " %+v", err_)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, m.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", m.Error())
+	}
+}
+
+func logCtxOfErr(err_ error) string {
+	if err_ == nil {
+		return ""
+	}
+	if logCtxer, ok := err_.(interface{ GetLogCtx() string }); ok {
+		return logCtxer.GetLogCtx()
+	}
+
+	return ""
+}
+
+// joinedResponse return the response with the highest status code of the
+// joined errors, the errors without response count as 500 so it's not
+// found when they are the most severe.
+func joinedResponse(errs []error) (Response, bool) {
+	var (
+		highest  Response
+		found    bool
+		unmapped bool
+	)
+
+	for _, err_ := range errs {
+		response, ok := GetResponse(err_)
+		if !ok {
+			unmapped = true
+			continue
+		}
+		if !found || response.StatusCode > highest.StatusCode {
+			highest, found = response, true
+		}
+	}
+
+	if unmapped && highest.StatusCode < http.StatusInternalServerError {
+		return Response{}, false
+	}
+
+	return highest, found
+}
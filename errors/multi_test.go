@@ -0,0 +1,94 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoin(t *testing.T) {
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+
+	errDuplicate := stderrors.New("duplicate key")
+	errLoanNotFound := New("TEST_MULTI_LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+	err := Join(nil, WrapWithErr(errDuplicate, ErrSQLExec), errLoanNotFound)
+
+	assert.Equal(t, "error sql exec: root cause : duplicate key\nLoan Not Found", err.Error())
+	assert.ErrorIs(t, err, errDuplicate)
+	assert.ErrorIs(t, err, ErrSQLExec)
+
+	var typed *Error
+	assert.ErrorAs(t, err, &typed)
+	assert.Equal(t, "TEST_MULTI_LOAN_NOT_FOUND", typed.Code())
+
+	formatted := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(formatted, "2 errors occurred:\n[1] "), formatted)
+	assert.Contains(t, formatted, "TestJoin: error sql exec: root cause : duplicate key")
+	assert.Contains(t, formatted, "\n[2] Loan Not Found")
+	assert.Contains(t, err.(*multiErr).GetLogCtx(), "TestJoin")
+}
+
+func TestGetResponseJoined(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			statusCode int
+			found      bool
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	errLoanNotFound := New("TEST_MULTI_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+
+	testCases := []testCase{
+		{
+			name: "HighestStatusCode",
+			args: args{err: Join(errLoanNotFound, Wrap(ErrSQLExec), ErrStaleObject)},
+			want: want{statusCode: http.StatusInternalServerError, found: true},
+		},
+		{
+			name: "ClientErrors",
+			args: args{err: Join(ErrInvalidPagination, errLoanNotFound)},
+			want: want{statusCode: http.StatusNotFound, found: true},
+		},
+		{
+			name: "WrappedJoin",
+			args: args{err: Wrap(Join(ErrInvalidPagination, ErrStaleObject))},
+			want: want{statusCode: http.StatusConflict, found: true},
+		},
+		{
+			name: "StdJoin",
+			args: args{err: stderrors.Join(ErrInvalidPagination, ErrStaleObject)},
+			want: want{statusCode: http.StatusConflict, found: true},
+		},
+		{
+			name: "UnmappedIsMostSevere",
+			args: args{err: Join(errLoanNotFound, stderrors.New("connection refused"))},
+			want: want{found: false},
+		},
+		{
+			name: "WithResponseWins",
+			args: args{err: WithResponse(Join(ErrSQLExec), http.StatusBadRequest, "Invalid Rows", nil)},
+			want: want{statusCode: http.StatusBadRequest, found: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			response, found := GetResponse(testCase.args.err)
+			assert.Equal(t, testCase.want.found, found)
+			assert.Equal(t, testCase.want.statusCode, response.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	"bitbucket.org/moladinTech/go-lib-common/registry"
+	commonTime "bitbucket.org/moladinTech/go-lib-common/time"
+)
+
+const (
+	LogLevelError = "error"
+	LogLevelWarn  = "warn"
+	LogLevelInfo  = "info"
+)
+
+var (
+	notifyPolicy   = NewNotifyPolicy()
+	notifyPolicyMu sync.RWMutex
+)
+
+// NotifyDecision is what to do with an error, Capture send it to sentry
+// and Notify send it to slack.
+type NotifyDecision struct {
+	Capture  bool
+	Notify   bool
+	LogLevel string
+}
+
+// NotifyRule decide the errors it matches, the empty fields match
+// everything. The first matching rule of the policy wins.
+type NotifyRule struct {
+	// StatusClass is the first digit of the status code, e.g. 4 for 4xx.
+	StatusClass int
+	// Err is matched with errors.Is, e.g. ErrSQLExec.
+	Err error
+	// Route is the gin full path, a trailing * match the prefix, e.g. /v1/loans/*.
+	Route string
+	// Env is the app environment, e.g. constant.EnvProduction.
+	Env string
+
+	NotifyDecision
+	// SampleRate is the ratio of the errors captured and notified, between
+	// 0 and 1, 0 means every error.
+	SampleRate float64
+	// DedupWindow skip the capture and the notification of the same error
+	// key within the window.
+	DedupWindow time.Duration
+}
+
+// NotifyParam is the error to decide, StatusCode is the status of the
+// response and Route the gin full path of the request.
+type NotifyParam struct {
+	Err        error
+	StatusCode int
+	Route      string
+	// Force is set by WithNotify, the error is captured and notified
+	// unless a rule decides otherwise.
+	Force bool
+}
+
+// NotifyPolicy decide whether errors are captured to sentry, notified to
+// slack and the level they are logged at.
+type NotifyPolicy struct {
+	mu     *sync.Mutex
+	rules  []NotifyRule
+	env    string
+	time   commonTime.TimeItf
+	sample func() float64
+	// dedupUntil is the end of the dedup window of the error keys sent.
+	dedupUntil map[string]time.Time
+}
+
+type NotifyPolicyOption func(*NotifyPolicy)
+
+// WithNotifyRule append rule to the policy, the rules are matched in
+// order.
+func WithNotifyRule(rule NotifyRule) NotifyPolicyOption {
+	return func(p *NotifyPolicy) {
+		p.rules = append(p.rules, rule)
+	}
+}
+
+// WithNotifyEnv set the app environment matched with NotifyRule.Env.
+func WithNotifyEnv(env string) NotifyPolicyOption {
+	return func(p *NotifyPolicy) {
+		p.env = env
+	}
+}
+
+// WithNotifyTime set the clock of the dedup windows, default time.Now.
+func WithNotifyTime(time commonTime.TimeItf) NotifyPolicyOption {
+	return func(p *NotifyPolicy) {
+		p.time = time
+	}
+}
+
+// WithNotifySampler set the source of the sampling, it should return a
+// number in [0, 1), default math/rand.
+func WithNotifySampler(sample func() float64) NotifyPolicyOption {
+	return func(p *NotifyPolicy) {
+		p.sample = sample
+	}
+}
+
+// NewNotifyPolicy return a policy, without rules the errors with status
+// 5xx or unknown response, the errors wrapped by this package, e.g. with
+// Wrap or WrapWithErr, and the forced errors are captured and notified.
+func NewNotifyPolicy(opts ...NotifyPolicyOption) *NotifyPolicy {
+	policy := &NotifyPolicy{
+		mu:         &sync.Mutex{},
+		time:       commonTime.InitTime(),
+		sample:     rand.Float64,
+		dedupUntil: make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(policy)
+	}
+
+	return policy
+}
+
+// SetNotifyPolicy replace the policy used by Report, call it on start up.
+func SetNotifyPolicy(policy *NotifyPolicy) {
+	notifyPolicyMu.Lock()
+	defer notifyPolicyMu.Unlock()
+
+	notifyPolicy = policy
+}
+
+// GetNotifyPolicy return the policy used by Report.
+func GetNotifyPolicy() *NotifyPolicy {
+	notifyPolicyMu.RLock()
+	defer notifyPolicyMu.RUnlock()
+
+	return notifyPolicy
+}
+
+// Decide return the decision of the first matching rule, with sampling
+// and dedup applied. An error already notified by WithNotify is never
+// captured nor notified again, a nil error is neither.
+func (p *NotifyPolicy) Decide(param NotifyParam) NotifyDecision {
+	if param.Err == nil {
+		return NotifyDecision{LogLevel: LogLevelError}
+	}
+	if param.StatusCode == 0 {
+		param.StatusCode = http.StatusInternalServerError
+	}
+
+	rule, ok := p.match(param)
+	if !ok {
+		_, isWrapped := param.Err.(*err)
+		isNotify := param.Force || isWrapped || param.StatusCode >= http.StatusInternalServerError
+		rule = NotifyRule{NotifyDecision: NotifyDecision{
+			Capture:  isNotify,
+			Notify:   isNotify,
+			LogLevel: LogLevelError,
+		}}
+	}
+
+	decision := rule.NotifyDecision
+	if decision.LogLevel == "" {
+		decision.LogLevel = LogLevelError
+	}
+	if !decision.Capture && !decision.Notify {
+		return decision
+	}
+
+	var wrapped *err
+	if stderrors.As(param.Err, &wrapped) && wrapped.isNotify {
+		decision.Capture, decision.Notify = false, false
+		return decision
+	}
+
+	if rule.SampleRate > 0 && p.sample() >= rule.SampleRate {
+		decision.Capture, decision.Notify = false, false
+		return decision
+	}
+
+	if rule.DedupWindow > 0 && p.isDuplicate(param.Err, rule.DedupWindow) {
+		decision.Capture, decision.Notify = false, false
+	}
+
+	return decision
+}
+
+func (p *NotifyPolicy) match(param NotifyParam) (NotifyRule, bool) {
+	for _, rule := range p.rules {
+		if rule.StatusClass != 0 && param.StatusCode/100 != rule.StatusClass {
+			continue
+		}
+		if rule.Err != nil && !stderrors.Is(param.Err, rule.Err) {
+			continue
+		}
+		if rule.Route != "" && !matchRoute(rule.Route, param.Route) {
+			continue
+		}
+		if rule.Env != "" && rule.Env != p.env {
+			continue
+		}
+
+		return rule, true
+	}
+
+	return NotifyRule{}, false
+}
+
+func matchRoute(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+
+	return pattern == route
+}
+
+// isDuplicate report whether the error key was sent within window, the
+// key is marked as sent otherwise. The keys whose window ended are
+// evicted so the keys don't grow unbounded.
+func (p *NotifyPolicy) isDuplicate(err_ error, window time.Duration) bool {
+	key := GetErrKey(err_).Error()
+	now := p.time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if until, ok := p.dedupUntil[key]; ok && now.Before(until) {
+		return true
+	}
+
+	for sentKey, until := range p.dedupUntil {
+		if !now.Before(until) {
+			delete(p.dedupUntil, sentKey)
+		}
+	}
+	p.dedupUntil[key] = now.Add(window)
+
+	return false
+}
+
+// Report log the error at the level decided by the policy set with
+// SetNotifyPolicy, then capture it to sentry and send the notification
+// when decided. A nil error is ignored.
+func Report(ctx context.Context, rgs registry.IRegistry, param NotifyParam) NotifyDecision {
+	if param.Err == nil {
+		return NotifyDecision{}
+	}
+
+	var (
+		decision = GetNotifyPolicy().Decide(param)
+		logCtx   string
+		tags     []logger.Tag
+	)
+
+	if logCtx = logCtxOfErr(param.Err); logCtx != "" {
+		tags = append(tags, logger.Tag{Key: "logCtx", Value: logCtx})
+	}
+
+	switch decision.LogLevel {
+	case LogLevelInfo:
+		logger.Info(ctx, param.Err.Error(), tags...)
+	case LogLevelWarn:
+		logger.Warn(ctx, param.Err.Error(), tags...)
+	default:
+		logger.Error(ctx, `error`, param.Err, tags...)
+	}
+
+	dispatch(ctx, rgs, decision, param.Err, logCtx)
+
+	return decision
+}
+
+func dispatch(ctx context.Context, rgs registry.IRegistry, decision NotifyDecision, err_ error, logCtx string) {
+	if decision.Capture {
+		rgs.GetSentry().CaptureException(err_)
+	}
+
+	if decision.Notify {
+		slackMessage := rgs.GetNotif().GetFormattedMessage(ctx, logCtx, notifyMessage(err_))
+		errSlack := rgs.GetNotif().Send(ctx, slackMessage)
+		if errSlack != nil {
+			logger.Error(ctx, "Error sending notif to slack", errSlack)
+		}
+	}
+}
+
+// notifyMessage return Error() and the fields of err_ without the stack
+// trace printed by %+v, the stack is kept for the logs and sentry. The
+// errors of Join are numbered like %+v does.
+func notifyMessage(err_ error) string {
+	var (
+		message strings.Builder
+		joined  *multiErr
+		wrapped *err
+	)
+
+	switch {
+	case stderrors.As(err_, &joined):
+		_, _ = fmt.Fprintf(&message, "%d errors occurred:", len(joined.errs))
+		for i, child := range joined.errs {
+			_, _ = fmt.Fprintf(&message, "\n[%d]", i+1)
+			if logCtx := logCtxOfErr(child); logCtx != "" {
+				_, _ = fmt.Fprintf(&message, " %s:", logCtx)
+			}
+			_, _ = fmt.Fprintf(&message, " %s", notifyMessage(child))
+		}
+	case stderrors.As(err_, &wrapped):
+		message.WriteString(err_.Error())
+		wrapped.writeFields(&message)
+	default:
+		message.WriteString(err_.Error())
+	}
+
+	return message.String()
+}
@@ -0,0 +1,231 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeTime) ToDateTime() string {
+	return f.now.Format("2006-01-02 15:04:05")
+}
+
+func TestNotifyPolicy_Decide(t *testing.T) {
+	type (
+		args struct {
+			opts  []NotifyPolicyOption
+			param NotifyParam
+		}
+		want struct {
+			decision NotifyDecision
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	var (
+		notify   = NotifyDecision{Capture: true, Notify: true, LogLevel: LogLevelError}
+		logError = NotifyDecision{LogLevel: LogLevelError}
+		logWarn  = NotifyDecision{LogLevel: LogLevelWarn}
+		notified = Wrap(stderrors.New("timeout"))
+	)
+	notified.isNotify = true
+
+	testCases := []testCase{
+		{
+			name: "DefaultServerError",
+			args: args{param: NotifyParam{Err: ErrSQLExec, StatusCode: http.StatusInternalServerError}},
+			want: want{decision: notify},
+		},
+		{
+			name: "DefaultUnknownResponse",
+			args: args{param: NotifyParam{Err: stderrors.New("unknown")}},
+			want: want{decision: notify},
+		},
+		{
+			name: "DefaultClientError",
+			args: args{param: NotifyParam{Err: ErrInvalidPagination, StatusCode: http.StatusBadRequest}},
+			want: want{decision: logError},
+		},
+		{
+			name: "DefaultWrappedClientError",
+			args: args{param: NotifyParam{Err: Wrap(ErrInvalidPagination), StatusCode: http.StatusBadRequest}},
+			want: want{decision: notify},
+		},
+		{
+			name: "NilError",
+			args: args{param: NotifyParam{StatusCode: http.StatusInternalServerError, Force: true}},
+			want: want{decision: logError},
+		},
+		{
+			name: "DefaultForced",
+			args: args{param: NotifyParam{Err: ErrInvalidPagination, StatusCode: http.StatusBadRequest, Force: true}},
+			want: want{decision: notify},
+		},
+		{
+			name: "AlreadyNotified",
+			args: args{param: NotifyParam{Err: notified}},
+			want: want{decision: logError},
+		},
+		{
+			name: "RuleByErrKey",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifyRule(NotifyRule{Err: ErrStaleObject, NotifyDecision: logWarn}),
+				},
+				param: NotifyParam{Err: Wrap(ErrStaleObject), StatusCode: http.StatusConflict},
+			},
+			want: want{decision: logWarn},
+		},
+		{
+			name: "RuleByStatusClassAndRoute",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifyRule(NotifyRule{StatusClass: 4, Route: "/v1/loans/*", NotifyDecision: NotifyDecision{Notify: true}}),
+				},
+				param: NotifyParam{Err: ErrMissingTenant, StatusCode: http.StatusForbidden, Route: "/v1/loans/:id"},
+			},
+			want: want{decision: NotifyDecision{Notify: true, LogLevel: LogLevelError}},
+		},
+		{
+			name: "RuleRouteNotMatch",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifyRule(NotifyRule{StatusClass: 4, Route: "/v1/loans/*", NotifyDecision: notify}),
+				},
+				param: NotifyParam{Err: ErrMissingTenant, StatusCode: http.StatusForbidden, Route: "/v1/users/:id"},
+			},
+			want: want{decision: logError},
+		},
+		{
+			name: "RuleByEnv",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifyEnv(constant.EnvDevelopment),
+					WithNotifyRule(NotifyRule{Env: constant.EnvDevelopment, NotifyDecision: logError}),
+				},
+				param: NotifyParam{Err: ErrSQLExec, StatusCode: http.StatusInternalServerError, Force: true},
+			},
+			want: want{decision: logError},
+		},
+		{
+			name: "RuleSampled",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifySampler(func() float64 { return 0.2 }),
+					WithNotifyRule(NotifyRule{StatusClass: 5, SampleRate: 0.5, NotifyDecision: notify}),
+				},
+				param: NotifyParam{Err: ErrSQLExec, StatusCode: http.StatusInternalServerError},
+			},
+			want: want{decision: notify},
+		},
+		{
+			name: "RuleNotSampled",
+			args: args{
+				opts: []NotifyPolicyOption{
+					WithNotifySampler(func() float64 { return 0.7 }),
+					WithNotifyRule(NotifyRule{StatusClass: 5, SampleRate: 0.5, NotifyDecision: notify}),
+				},
+				param: NotifyParam{Err: ErrSQLExec, StatusCode: http.StatusInternalServerError},
+			},
+			want: want{decision: logError},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			policy := NewNotifyPolicy(testCase.args.opts...)
+			assert.Equal(t, testCase.want.decision, policy.Decide(testCase.args.param))
+		})
+	}
+}
+
+func TestNotifyPolicy_DecideDedup(t *testing.T) {
+	clock := &fakeTime{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	policy := NewNotifyPolicy(
+		WithNotifyTime(clock),
+		WithNotifyRule(NotifyRule{StatusClass: 5, DedupWindow: time.Minute, NotifyDecision: NotifyDecision{Capture: true, Notify: true}}),
+	)
+	param := NotifyParam{Err: WrapWithErr(stderrors.New("connection refused"), ErrSQLExec), StatusCode: http.StatusInternalServerError}
+
+	assert.True(t, policy.Decide(param).Notify)
+
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.False(t, policy.Decide(param).Notify)
+	assert.True(t, policy.Decide(NotifyParam{Err: ErrBulkInsert, StatusCode: http.StatusInternalServerError}).Notify)
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.True(t, policy.Decide(param).Notify)
+}
+
+func TestNotifyPolicy_DecideDedupEviction(t *testing.T) {
+	clock := &fakeTime{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	policy := NewNotifyPolicy(
+		WithNotifyTime(clock),
+		WithNotifyRule(NotifyRule{DedupWindow: time.Minute, NotifyDecision: NotifyDecision{Notify: true}}),
+	)
+
+	assert.True(t, policy.Decide(NotifyParam{Err: ErrSQLExec}).Notify)
+	assert.True(t, policy.Decide(NotifyParam{Err: ErrBulkInsert}).Notify)
+	assert.Len(t, policy.dedupUntil, 2)
+
+	// the keys whose window ended are evicted on the next send
+	clock.now = clock.now.Add(time.Minute)
+	assert.True(t, policy.Decide(NotifyParam{Err: ErrStaleObject}).Notify)
+	assert.Len(t, policy.dedupUntil, 1)
+}
+
+func TestNotifyMessage(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			message string
+		}
+		testCase struct {
+			name string
+			args args
+			want want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Error() and the fields without the stack",
+			args: args{err: With(ErrSQLExec, "table", "loans", "id", 7)},
+			want: want{message: ErrSQLExec.Error() + " id=7 table=loans"},
+		},
+		{
+			name: "joined errors are numbered",
+			args: args{err: Join(With(ErrSQLExec, "row", 1), stderrors.New("boom"))},
+			want: want{message: "2 errors occurred:\n[1] " + logCtxOfErr(With(ErrSQLExec)) + ": " + ErrSQLExec.Error() + " row=1\n[2] boom"},
+		},
+		{
+			name: "not wrapped error",
+			args: args{err: stderrors.New("boom")},
+			want: want{message: "boom"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want.message, notifyMessage(tc.args.err))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+)
+
+// responseError carry the response of a single request, unlike
+// SetDataErrCustom which is shared by every request.
+type responseError struct {
+	err      error
+	response Response
+}
+
+// WithResponse return err with its own response, err is ErrCustom when
+// nil. errors.Is and errors.As still match err.
+//
+//	return errors.WithResponse(errors.ErrCustom, http.StatusBadRequest, "Loan Is Closed", loan)
+func WithResponse(err error, statusCode int, message string, data any) error {
+	if err == nil {
+		err = ErrCustom
+	}
+
+	return &responseError{
+		err: err,
+		response: Response{
+			StatusCode: statusCode,
+			Response: responseModel.Response{
+				Status:  responseModel.StatusFail,
+				Message: message,
+				Data:    data,
+			},
+		},
+	}
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
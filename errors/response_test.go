@@ -0,0 +1,84 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithResponse(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			response Response
+			is       error
+			message  string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	response := Response{
+		StatusCode: http.StatusBadRequest,
+		Response: responseModel.Response{
+			Status:  responseModel.StatusFail,
+			Message: "Loan Is Closed",
+			Data:    1,
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "ErrCustom",
+			args: args{err: WithResponse(nil, http.StatusBadRequest, "Loan Is Closed", 1)},
+			want: want{response: response, is: ErrCustom, message: ErrCustom.Error()},
+		},
+		{
+			name: "OverrideMapErrorResponse",
+			args: args{err: WithResponse(ErrSQLExec, http.StatusBadRequest, "Loan Is Closed", 1)},
+			want: want{response: response, is: ErrSQLExec, message: ErrSQLExec.Error()},
+		},
+		{
+			name: "Wrapped",
+			args: args{err: WrapWithErr(stderrors.New("closed"), WithResponse(ErrCustom, http.StatusBadRequest, "Loan Is Closed", 1))},
+			want: want{response: response, is: ErrCustom, message: ErrCustom.Error()},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, ok := GetResponse(testCase.args.err)
+			assert.True(t, ok)
+			assert.Equal(t, testCase.want.response, actual)
+			assert.ErrorIs(t, testCase.args.err, testCase.want.is)
+			assert.Equal(t, testCase.want.message, GetErrKey(testCase.args.err).Error())
+		})
+	}
+}
+
+func TestWithResponseConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			message := fmt.Sprintf("request %d", i)
+
+			response, ok := GetResponse(WithResponse(ErrCustom, http.StatusBadRequest, message, i))
+			assert.True(t, ok)
+			assert.Equal(t, message, response.Response.(responseModel.Response).Message)
+			assert.Equal(t, i, response.Response.(responseModel.Response).Data)
+		}(i)
+	}
+	wg.Wait()
+}
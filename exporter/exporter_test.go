@@ -618,6 +618,42 @@ func (e *ExporterSuite) TestEmbeddedStructExportExcel() {
 	}
 }
 
+func (e *ExporterSuite) TestCSVStreamWriter() {
+	var buffer bytes.Buffer
+	writer := exporter.NewCSVStreamWriter(&buffer, exporter.AddConverter(TestStruct{}, TestStruct{}.GenerateConverter()))
+
+	batches := [][]TestStruct{
+		{{Name: "First", When: cast.NewPointer(commonTime.DateTime(time.Date(2022, 3, 28, 9, 0, 0, 0, time.UTC)))}},
+		{{Name: "Second", When: cast.NewPointer(commonTime.DateTime(time.Date(2022, 3, 28, 10, 0, 0, 0, time.UTC)))}},
+	}
+	for _, batch := range batches {
+		e.Nil(writer.Write(batch))
+	}
+	e.Nil(writer.Flush())
+	e.ErrorIs(writer.Write(TestStruct{}), exporter.ErrorExporterNotSupportedType)
+
+	data, err := csv.NewReader(bytes.NewReader(buffer.Bytes())).ReadAll()
+	e.Nil(err)
+	e.EqualValues([][]string{
+		{"Name", "When"},
+		{"First", "9:00AM"},
+		{"Second", "10:00AM"},
+	}, data)
+}
+
+func (e *ExporterSuite) TestCSVStreamWriterNil() {
+	var buffer bytes.Buffer
+	writer := exporter.NewCSVStreamWriter(&buffer)
+
+	e.ErrorIs(writer.Write(nil), exporter.ErrorExporterNotSupportedType)
+	e.Nil(writer.Write([]TestStruct(nil)))
+	e.Nil(writer.Flush())
+
+	data, err := csv.NewReader(bytes.NewReader(buffer.Bytes())).ReadAll()
+	e.Nil(err)
+	e.EqualValues([][]string{{"Name", "When"}}, data)
+}
+
 func TestExporter(t *testing.T) {
 	t.Parallel()
 	suite.Run(t, new(ExporterSuite))
@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+)
+
+// CSVStreamWriter write slices of struct to w as csv batch by batch, so
+// large exports don't have to hold every row in memory. The header is
+// written with the first batch.
+type CSVStreamWriter struct {
+	writer        *csv.Writer
+	exporter      exporterCSV
+	headerWritten bool
+}
+
+// NewCSVStreamWriter create a CSVStreamWriter, only AddConverter options
+// are used.
+func NewCSVStreamWriter(w io.Writer, opt ...Option) *CSVStreamWriter {
+	exporter := &exporter{
+		converter: make(map[string]map[string]FuncConvert),
+	}
+
+	for _, option := range opt {
+		option(exporter)
+	}
+
+	return &CSVStreamWriter{
+		writer:   csv.NewWriter(w),
+		exporter: exporterCSV(exporter.converter),
+	}
+}
+
+// Write write the rows of v, v should be a slice of struct. A nil slice
+// of struct only write the header, an untyped nil has no header and
+// return ErrorExporterNotSupportedType.
+func (s *CSVStreamWriter) Write(v interface{}) error {
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.Slice {
+		return ErrorExporterNotSupportedType
+	}
+
+	rows := reflect.ValueOf(v)
+	structElem := rows.Type().Elem()
+	if structElem.Kind() == reflect.Pointer {
+		structElem = structElem.Elem()
+	}
+
+	header := s.exporter.ExtractHeader(reflect.New(structElem).Elem())
+	if !s.headerWritten {
+		if err := s.writer.Write(header); err != nil {
+			return err
+		}
+		s.headerWritten = true
+	}
+
+	convFunc := s.exporter[structElem.Name()]
+	for i := 0; i < rows.Len(); i++ {
+		t := rows.Index(i)
+		if t.Type().Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if err := s.writer.Write(s.exporter.ExtractRow(t, len(header), convFunc)); err != nil {
+			return err
+		}
+	}
+
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// Flush write any buffered data, call it after the last batch.
+func (s *CSVStreamWriter) Flush() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
@@ -0,0 +1,203 @@
+//go:generate mockery --name=IHealth
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	commonValidator "bitbucket.org/moladinTech/go-lib-common/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+
+	DefaultTimeout = 5 * time.Second
+)
+
+// Checker is implemented by every dependency having a Health method, e.g.
+// moladin_evo, slack or data_source.DBHealth.
+type Checker interface {
+	Health(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Health(ctx context.Context) error {
+	return f(ctx)
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Result struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type IHealth interface {
+	Register(name string, checker Checker)
+	Check(ctx context.Context) Result
+	Handler() gin.HandlerFunc
+}
+
+type HealthPackage struct {
+	mu       *sync.RWMutex
+	checkers map[string]Checker
+	Timeout  time.Duration `validate:"required"`
+}
+
+type Option func(*HealthPackage)
+
+func WithChecker(name string, checker Checker) Option {
+	return func(h *HealthPackage) {
+		h.checkers[name] = checker
+	}
+}
+
+// WithTimeout set the timeout of every check, default 5 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(h *HealthPackage) {
+		h.Timeout = timeout
+	}
+}
+
+func NewHealth(
+	validator *validator.Validate,
+	options ...Option,
+) *HealthPackage {
+	healthPkg := &HealthPackage{
+		mu:       &sync.RWMutex{},
+		checkers: make(map[string]Checker),
+		Timeout:  DefaultTimeout,
+	}
+
+	for _, option := range options {
+		option(healthPkg)
+	}
+
+	err := validator.Struct(healthPkg)
+	if err != nil {
+		panic(commonValidator.ToErrResponse(err))
+	}
+
+	return healthPkg
+}
+
+// Register add or replace the checker of name.
+func (h *HealthPackage) Register(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers[name] = checker
+}
+
+// Check run every checker concurrently, the result is DOWN when any of
+// them fail or doesn't answer within the timeout.
+func (h *HealthPackage) Check(ctx context.Context) Result {
+	const logCtx = "common.health.Check"
+
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checkers))
+	checkers := make([]Checker, 0, len(h.checkers))
+	for name, checker := range h.checkers {
+		names = append(names, name)
+		checkers = append(checkers, checker)
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i := range checkers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = h.check(ctx, checkers[i])
+		}(i)
+	}
+	wg.Wait()
+
+	result := Result{Status: StatusUp, Checks: make(map[string]CheckResult, len(checkers))}
+	for i, name := range names {
+		result.Checks[name] = results[i]
+		if results[i].Status == StatusDown {
+			result.Status = StatusDown
+		}
+	}
+
+	if result.Status == StatusDown {
+		down := make([]string, 0)
+		for name, check := range result.Checks {
+			if check.Status == StatusDown {
+				down = append(down, name)
+			}
+		}
+		sort.Strings(down)
+		logger.Warn(ctx, "health check down",
+			logger.Tag{Key: "logCtx", Value: logCtx},
+			logger.Tag{Key: "checks", Value: down},
+		)
+	}
+
+	return result
+}
+
+func (h *HealthPackage) check(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- checker.Health(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusUp, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Handler answer 200 when every check is UP and 503 otherwise, with the
+// Result in data.
+func (h *HealthPackage) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := h.Check(c.Request.Context())
+
+		if result.Status == StatusDown {
+			c.JSON(http.StatusServiceUnavailable, responseModel.Response{
+				Status:  responseModel.StatusFail,
+				Message: http.StatusText(http.StatusServiceUnavailable),
+				Data:    result,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, responseModel.Response{
+			Status:  responseModel.StatusSuccess,
+			Message: http.StatusText(http.StatusOK),
+			Data:    result,
+		})
+	}
+}
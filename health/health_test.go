@@ -0,0 +1,84 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-common/health"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Handler(t *testing.T) {
+	t.Parallel()
+	type (
+		args struct {
+			checker health.Checker
+		}
+		want struct {
+			statusCode int
+			status     string
+			err        string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Up",
+			args: args{checker: health.CheckerFunc(func(ctx context.Context) error { return nil })},
+			want: want{statusCode: http.StatusOK, status: health.StatusUp},
+		},
+		{
+			name: "Down",
+			args: args{checker: health.CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })},
+			want: want{statusCode: http.StatusServiceUnavailable, status: health.StatusDown, err: "connection refused"},
+		},
+		{
+			name: "Timeout",
+			args: args{checker: health.CheckerFunc(func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			})},
+			want: want{statusCode: http.StatusServiceUnavailable, status: health.StatusDown, err: context.DeadlineExceeded.Error()},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			healthPkg := health.NewHealth(validator.New(),
+				health.WithTimeout(50*time.Millisecond),
+				health.WithChecker("cache", health.CheckerFunc(func(ctx context.Context) error { return nil })),
+			)
+			healthPkg.Register("db", testCase.args.checker)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/health", healthPkg.Handler())
+
+			req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var resp struct {
+				Data health.Result `json:"data"`
+			}
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, testCase.want.statusCode, w.Code)
+			assert.Equal(t, testCase.want.status, resp.Data.Status)
+			assert.Equal(t, health.StatusUp, resp.Data.Checks["cache"].Status)
+			assert.Equal(t, testCase.want.status, resp.Data.Checks["db"].Status)
+			assert.Equal(t, testCase.want.err, resp.Data.Checks["db"].Error)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	LocaleEN = "en"
+	LocaleID = "id"
+
+	DefaultLocale = LocaleEN
+
+	HeaderAcceptLanguage = "Accept-Language"
+)
+
+var catalogs = struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}{messages: map[string]map[string]string{
+	LocaleEN: enMessages,
+	LocaleID: idMessages,
+}}
+
+// Register add or replace the messages of locale, the messages are
+// fmt formats, e.g. "%[1]s must be a minimum of %[2]s in length".
+func Register(locale string, messages map[string]string) {
+	locale = normalize(locale)
+
+	catalogs.mu.Lock()
+	defer catalogs.mu.Unlock()
+
+	if _, ok := catalogs.messages[locale]; !ok {
+		catalogs.messages[locale] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		catalogs.messages[locale][key] = message
+	}
+}
+
+// Lookup return the message of key formatted with args, the language of
+// locale is used when the region has no message, e.g. id for id-ID.
+func Lookup(locale, key string, args ...any) (string, bool) {
+	locale = normalize(locale)
+	language, _, _ := strings.Cut(locale, "-")
+
+	catalogs.mu.RLock()
+	message, ok := catalogs.messages[locale][key]
+	if !ok {
+		message, ok = catalogs.messages[language][key]
+	}
+	catalogs.mu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return message, true
+	}
+
+	return fmt.Sprintf(message, args...), true
+}
+
+// Translate return the message of key in locale, falling back to the
+// DefaultLocale then to key.
+func Translate(locale, key string, args ...any) string {
+	if message, ok := Lookup(locale, key, args...); ok {
+		return message
+	}
+	if message, ok := Lookup(DefaultLocale, key, args...); ok {
+		return message
+	}
+
+	return key
+}
+
+// Locales return the registered locales sorted.
+func Locales() []string {
+	catalogs.mu.RLock()
+	defer catalogs.mu.RUnlock()
+
+	locales := make([]string, 0, len(catalogs.messages))
+	for locale := range catalogs.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Match return the registered locale preferred by the Accept-Language
+// header, e.g. "id-ID,id;q=0.9,en;q=0.8" return id, DefaultLocale when
+// none is registered.
+func Match(acceptLanguage string) string {
+	type preference struct {
+		locale string
+		q      float64
+	}
+
+	preferences := make([]preference, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		preferences = append(preferences, preference{locale: normalize(tag), q: q})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].q > preferences[j].q
+	})
+
+	catalogs.mu.RLock()
+	defer catalogs.mu.RUnlock()
+
+	for _, preference := range preferences {
+		if _, ok := catalogs.messages[preference.locale]; ok {
+			return preference.locale
+		}
+		language, _, _ := strings.Cut(preference.locale, "-")
+		if _, ok := catalogs.messages[language]; ok {
+			return language
+		}
+	}
+
+	return DefaultLocale
+}
+
+// FromRequest return the locale matching the Accept-Language header of r.
+func FromRequest(r *http.Request) string {
+	if r == nil {
+		return DefaultLocale
+	}
+
+	return Match(r.Header.Get(HeaderAcceptLanguage))
+}
+
+// normalize lower the locale and use - as separator, e.g. id_ID to id-id.
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
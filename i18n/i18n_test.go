@@ -0,0 +1,105 @@
+package i18n_test
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/moladinTech/go-lib-common/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	type (
+		args struct {
+			acceptLanguage string
+		}
+		want struct {
+			locale string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{name: "Empty", args: args{acceptLanguage: ""}, want: want{locale: i18n.LocaleEN}},
+		{name: "Language", args: args{acceptLanguage: "id"}, want: want{locale: i18n.LocaleID}},
+		{name: "Region", args: args{acceptLanguage: "id-ID,id;q=0.9,en;q=0.8"}, want: want{locale: i18n.LocaleID}},
+		{name: "Quality", args: args{acceptLanguage: "en;q=0.5, id;q=0.9"}, want: want{locale: i18n.LocaleID}},
+		{name: "Unsupported", args: args{acceptLanguage: "fr-FR,fr;q=0.9,*;q=0.5"}, want: want{locale: i18n.DefaultLocale}},
+		{name: "Excluded", args: args{acceptLanguage: "id;q=0,en;q=0.3"}, want: want{locale: i18n.LocaleEN}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want.locale, i18n.Match(testCase.args.acceptLanguage))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	i18n.Register("id", map[string]string{"TEST_LOAN_NOT_FOUND": "Pinjaman %[1]d Tidak Ditemukan"})
+	i18n.Register("jv", map[string]string{"validation.required": "%[1]s kudu diisi"})
+
+	type (
+		args struct {
+			locale string
+			key    string
+			args   []any
+		}
+		want struct {
+			message string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Registered",
+			args: args{locale: "id", key: "TEST_LOAN_NOT_FOUND", args: []any{1}},
+			want: want{message: "Pinjaman 1 Tidak Ditemukan"},
+		},
+		{
+			name: "RegisteredLocale",
+			args: args{locale: "jv", key: "validation.required", args: []any{"amount", ""}},
+			want: want{message: "amount kudu diisi"},
+		},
+		{
+			name: "RegionFallbackToLanguage",
+			args: args{locale: "id_ID", key: "validation.required", args: []any{"amount", ""}},
+			want: want{message: "amount wajib diisi"},
+		},
+		{
+			name: "FallbackToDefaultLocale",
+			args: args{locale: "jv", key: "validation.url", args: []any{"link", ""}},
+			want: want{message: "link must be a valid URL"},
+		},
+		{
+			name: "FallbackToKey",
+			args: args{locale: "id", key: "UNKNOWN"},
+			want: want{message: "UNKNOWN"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want.message, i18n.Translate(testCase.args.locale, testCase.args.key, testCase.args.args...))
+		})
+	}
+
+	assert.Contains(t, i18n.Locales(), "jv")
+}
+
+func TestFromRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(i18n.HeaderAcceptLanguage, "id-ID")
+
+	assert.Equal(t, i18n.LocaleID, i18n.FromRequest(req))
+	assert.Equal(t, i18n.DefaultLocale, i18n.FromRequest(nil))
+}
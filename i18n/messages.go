@@ -0,0 +1,56 @@
+package i18n
+
+// The keys of the validation messages are the validator tags prefixed by
+// validation, their args are the field and the param of the tag. The keys
+// of the error messages are the message key of the typed errors, the code
+// by default.
+var enMessages = map[string]string{
+	"validation.required":             "%[1]s is a required field",
+	"validation.len":                  "%[1]s must be a %[2]s length",
+	"validation.min":                  "%[1]s must be a minimum of %[2]s in length",
+	"validation.max":                  "%[1]s must be a maximum of %[2]s in length",
+	"validation.url":                  "%[1]s must be a valid URL",
+	"validation.oneof":                "%[1]s must be an oneof [%[2]s]",
+	"validation.required_if":          "%[1]s is a required if %[2]s",
+	"validation.required_unless":      "%[1]s is a required if %[2]s",
+	"validation.required_without":     "%[1]s is a required if %[2]s is empty",
+	"validation.required_without_all": "%[1]s is a required if %[2]s are empty",
+	"validation.required_with":        "%[1]s is a required if %[2]s is not empty",
+	"validation.excluded_with":        "%[1]s is a exclude if %[2]s is empty",
+	"validation.ltecsfield":           "%[1]s is less than to another %[2]s field",
+	"validation.default":              "something wrong on %[1]s; %[2]s",
+	"validation.is":                   "is",
+	"validation.is_not":               "is not",
+	"validation.and":                  "and",
+}
+
+var idMessages = map[string]string{
+	"validation.required":             "%[1]s wajib diisi",
+	"validation.len":                  "panjang %[1]s harus %[2]s",
+	"validation.min":                  "panjang minimal %[1]s adalah %[2]s",
+	"validation.max":                  "panjang maksimal %[1]s adalah %[2]s",
+	"validation.url":                  "%[1]s harus berupa URL yang valid",
+	"validation.oneof":                "%[1]s harus salah satu dari [%[2]s]",
+	"validation.required_if":          "%[1]s wajib diisi jika %[2]s",
+	"validation.required_unless":      "%[1]s wajib diisi jika %[2]s",
+	"validation.required_without":     "%[1]s wajib diisi jika %[2]s kosong",
+	"validation.required_without_all": "%[1]s wajib diisi jika %[2]s kosong",
+	"validation.required_with":        "%[1]s wajib diisi jika %[2]s tidak kosong",
+	"validation.excluded_with":        "%[1]s harus kosong jika %[2]s kosong",
+	"validation.ltecsfield":           "%[1]s harus kurang dari atau sama dengan %[2]s",
+	"validation.default":              "terjadi kesalahan pada %[1]s; %[2]s",
+	"validation.is":                   "adalah",
+	"validation.is_not":               "bukan",
+	"validation.and":                  "dan",
+
+	"SQL_QUERY_BUILDER":  "Terjadi Kesalahan Pada Server",
+	"SQL_EXEC":           "Database Gagal Memproses Permintaan, Silakan Coba Lagi",
+	"MESSAGE_REQUIRED":   "Pesan Wajib Diisi",
+	"MIGRATE":            "Gagal Melakukan Migrasi Database",
+	"INVALID_PAGINATION": "Parameter Page, Limit, Sort Atau Filter Tidak Valid",
+	"BULK_INSERT":        "Gagal Menyimpan Data",
+	"STALE_OBJECT":       "Data Telah Diubah Oleh Permintaan Lain, Silakan Muat Ulang Dan Coba Lagi",
+	"MISSING_TENANT":     "Akses Ditolak",
+	"INVALID_REQUEST":    "Permintaan Tidak Valid",
+	"VALIDATION":         "Data Tidak Valid",
+}
@@ -2,12 +2,15 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 
 	"bitbucket.org/moladinTech/go-lib-common/constant"
 	"github.com/gin-gonic/gin"
+	pkgErrors "github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
 )
@@ -33,7 +36,7 @@ func Init(config Config) {
 	if config.Debug {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
 	}
-	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+	zerolog.ErrorStackMarshaler = MarshalStack
 
 	Logger.ZeroLogger = zerolog.New(zerolog.SyncWriter(os.Stdout)).With().
 		Caller().
@@ -42,6 +45,25 @@ func Init(config Config) {
 		Logger()
 }
 
+type stackTracer struct {
+	error
+	stack pkgErrors.StackTrace
+}
+
+func (s stackTracer) StackTrace() pkgErrors.StackTrace {
+	return s.stack
+}
+
+// MarshalStack marshal the stack of the errors wrapped by the common errors
+// package, the other errors are marshaled by pkgerrors.MarshalStack.
+func MarshalStack(err error) interface{} {
+	if stacker, ok := err.(interface{ Stack() pkgErrors.StackTrace }); ok {
+		return pkgerrors.MarshalStack(stackTracer{error: err, stack: stacker.Stack()})
+	}
+
+	return pkgerrors.MarshalStack(err)
+}
+
 func writeZeroLog(ev *zerolog.Event, tags ...Tag) *zerolog.Event {
 	for _, t := range tags {
 		ev = ev.Str(t.Key, fmt.Sprintf("%+v", t.Value))
@@ -90,11 +112,63 @@ func Error(ctx context.Context, msg string, err error, tags ...Tag) {
 		append(tags, GetAllLoggingTagInTagStr(ctx)...)...,
 	)
 	if err != nil {
-		ev.Err(err)
+		ev = writeZeroLog(ev, errFieldTags(err)...)
+		if errs := joinedErrors(err); errs != nil {
+			ev = ev.Interface("errors", errs)
+		}
+		// the stack of err is logged by zerolog.ErrorStackMarshaler
+		ev.Stack().Err(err)
 	}
 	ev.Strs("stack_trace", getStackTrace(2, 5)).Msg(msg)
 }
 
+// errFieldTags return the fields attached to err with errors.With.
+func errFieldTags(err error) []Tag {
+	var fielder interface{ Fields() map[string]any }
+	if !errors.As(err, &fielder) {
+		return nil
+	}
+
+	fields := fielder.Fields()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make([]Tag, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, Tag{Key: key, Value: fields[key]})
+	}
+
+	return tags
+}
+
+// joinedErrors return the errors joined with errors.Join, each with its
+// logCtx and stack.
+func joinedErrors(err error) []map[string]any {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return nil
+	}
+
+	errs := make([]map[string]any, 0)
+	for _, child := range joined.Unwrap() {
+		fields := map[string]any{"error": child.Error()}
+		if logCtxer, ok := child.(interface{ GetLogCtx() string }); ok && logCtxer.GetLogCtx() != "" {
+			fields["logCtx"] = logCtxer.GetLogCtx()
+		}
+		if zerolog.ErrorStackMarshaler != nil {
+			if stack := zerolog.ErrorStackMarshaler(child); stack != nil {
+				fields["stack"] = stack
+			}
+		}
+		errs = append(errs, fields)
+	}
+
+	return errs
+}
+
 // Fatal to provide global zerolog log Fatal
 func Fatal(ctx context.Context, msg string, tags ...Tag) {
 	writeZeroLog(
@@ -1,16 +1,21 @@
 package logger_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonErrors "bitbucket.org/moladinTech/go-lib-common/errors"
 	commonLogger "bitbucket.org/moladinTech/go-lib-common/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,3 +47,55 @@ func Test_NewContextFromParent(t *testing.T) {
 
 	assert.Equal(t, expectedCtx, ctx)
 }
+
+func Test_ErrorWithFieldsAndStack(t *testing.T) {
+	var buf bytes.Buffer
+	zerologger := commonLogger.Logger.ZeroLogger
+	stackMarshaler := zerolog.ErrorStackMarshaler
+	t.Cleanup(func() {
+		commonLogger.Logger.ZeroLogger = zerologger
+		zerolog.ErrorStackMarshaler = stackMarshaler
+	})
+	commonLogger.Logger.ZeroLogger = zerolog.New(&buf)
+	zerolog.ErrorStackMarshaler = commonLogger.MarshalStack
+
+	err := commonErrors.With(errors.New("loan not found"), "loan_id", 10)
+	commonLogger.Error(context.Background(), "error", err)
+
+	var logged map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &logged))
+	assert.Equal(t, "loan not found", logged["error"])
+	assert.Equal(t, "10", logged["loan_id"])
+	assert.NotEmpty(t, logged["stack"])
+}
+
+func Test_ErrorWithJoinedErrors(t *testing.T) {
+	var buf bytes.Buffer
+	zerologger := commonLogger.Logger.ZeroLogger
+	stackMarshaler := zerolog.ErrorStackMarshaler
+	t.Cleanup(func() {
+		commonLogger.Logger.ZeroLogger = zerologger
+		zerolog.ErrorStackMarshaler = stackMarshaler
+	})
+	commonLogger.Logger.ZeroLogger = zerolog.New(&buf)
+	zerolog.ErrorStackMarshaler = commonLogger.MarshalStack
+
+	err := commonErrors.Join(
+		commonErrors.Wrap(errors.New("row 1 duplicate")),
+		errors.New("row 2 invalid"),
+	)
+	commonLogger.Error(context.Background(), "error", err)
+
+	var logged struct {
+		Error  string           `json:"error"`
+		Errors []map[string]any `json:"errors"`
+	}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &logged))
+	assert.Equal(t, "row 1 duplicate\nrow 2 invalid", logged.Error)
+	assert.Len(t, logged.Errors, 2)
+	assert.Equal(t, "row 1 duplicate", logged.Errors[0]["error"])
+	assert.Contains(t, logged.Errors[0]["logCtx"], "Test_ErrorWithJoinedErrors")
+	assert.NotEmpty(t, logged.Errors[0]["stack"])
+	assert.Equal(t, "row 2 invalid", logged.Errors[1]["error"])
+	assert.Nil(t, logged.Errors[1]["logCtx"])
+}
@@ -13,6 +13,7 @@ import (
 	"bitbucket.org/moladinTech/go-lib-activity-log/model"
 	moladinEvoClient "bitbucket.org/moladinTech/go-lib-common/client/moladin_evo"
 	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
 	"bitbucket.org/moladinTech/go-lib-common/logger"
 	"bitbucket.org/moladinTech/go-lib-common/middleware/gin/auth/rbac"
 	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
@@ -33,8 +34,15 @@ const (
 	XSTEvo       XUserType = "EVO"
 	XSTSignature XUserType = "Signature"
 	XSTAPIKey    XUserType = "APIKey"
+	XSTJWT       XUserType = "JWT"
 )
 
+// TenantResolver resolve the tenant, e.g. the dealer or branch id, from the
+// user detail set by the middleware: *model.Me of go-lib-rbac for RBAC and
+// model.UserDetail of go-lib-activity-log otherwise. ok is false when the
+// user doesn't belong to any tenant.
+type TenantResolver func(ctx context.Context, userType XUserType, userDetail any) (tenantID any, ok bool)
+
 type IMiddlewareAuth interface {
 	Auth(rbacPermissions []string) gin.HandlerFunc
 	AuthToken() gin.HandlerFunc
@@ -42,6 +50,7 @@ type IMiddlewareAuth interface {
 	AuthSignature() gin.HandlerFunc
 	AuthRoleRBAC(allowedRoles []string) gin.HandlerFunc
 	AuthPermissionRBAC(allowedPermissions []string) gin.HandlerFunc
+	AuthJWT(allowedPermissions []string) gin.HandlerFunc
 }
 
 type MiddlewareAuthPackage struct {
@@ -54,6 +63,9 @@ type MiddlewareAuthPackage struct {
 	PermittedRoles          []string
 	ServiceName             string
 	SecretKey               string
+	TenantResolver          TenantResolver
+	JWTVerifier             *JWTVerifier
+	UserCache               *UserCache
 }
 
 func WithSentry(sentry sentry.ISentry) Option {
@@ -110,6 +122,29 @@ func WithRBACClient(rbacClient rbac.IClientRBAC) Option {
 	}
 }
 
+// WithTenantResolver put the tenant of the authenticated user in the
+// request context, see context.GetTenant.
+func WithTenantResolver(tenantResolver TenantResolver) Option {
+	return func(s *MiddlewareAuthPackage) {
+		s.TenantResolver = tenantResolver
+	}
+}
+
+// WithJWTVerifier verify the bearer JWTs locally, see NewJWTVerifier.
+func WithJWTVerifier(jwtVerifier *JWTVerifier) Option {
+	return func(s *MiddlewareAuthPackage) {
+		s.JWTVerifier = jwtVerifier
+	}
+}
+
+// WithUserCache cache the user detail resolved by RBAC and moladin evo,
+// see NewUserCache.
+func WithUserCache(userCache *UserCache) Option {
+	return func(s *MiddlewareAuthPackage) {
+		s.UserCache = userCache
+	}
+}
+
 type Option func(*MiddlewareAuthPackage)
 
 func NewAuth(
@@ -154,6 +189,29 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 		}
 
 		if authorizationToken != "" {
+			if a.JWTVerifier != nil {
+				token := strings.TrimPrefix(authorizationToken, "Bearer ")
+				if IsJWT(token) {
+					claims, user, err := a.JWTVerifier.UserDetail(reqCtx, token)
+					if err != nil {
+						// the token may be verified by the next modes, e.g. moladin evo
+						logger.Warn(reqCtx, err.Error(), logger.Tag{
+							Key:   "logCtx",
+							Value: logCtx,
+						})
+					}
+
+					if err == nil && isJWTPermitted(claims, rbacPermissions) {
+						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
+						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTJWT))
+						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, user))
+						a.setTenant(gc, XSTJWT)
+						gc.Next()
+						return
+					}
+				}
+			}
+
 			if a.RBACClient != nil {
 				token := strings.Split(authorizationToken, " ")
 				if len(token) < 2 {
@@ -166,9 +224,9 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 
 				_, err := uuid.Parse(token[1])
 				if err == nil {
-					isPermitted, user, errCheck := a.RBACClient.IsPermissionAllowed(rbacPermissions, token[1])
+					isPermitted, user, errCheck := a.isPermissionAllowed(reqCtx, rbacPermissions, token[1])
 					if errCheck != nil {
-						logger.Error(gc.Request.Context(), err.Error(), err, logger.Tag{
+						logger.Error(gc.Request.Context(), errCheck.Error(), errCheck, logger.Tag{
 							Key:   "logCtx",
 							Value: logCtx,
 						})
@@ -178,6 +236,7 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
 						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTRBAC))
 						gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, user))
+						a.setTenant(gc, XSTRBAC)
 						gc.Next()
 						return
 					}
@@ -185,7 +244,7 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 			}
 
 			if a.MoladinEvoClient != nil {
-				user, err := a.MoladinEvoClient.UserDetail(gc.Request.Context(), authorizationToken)
+				user, err := a.evoUserDetail(gc.Request.Context(), authorizationToken)
 				if err != nil {
 					logger.Error(gc.Request.Context(), err.Error(), err, logger.Tag{
 						Key:   "logCtx",
@@ -202,6 +261,7 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 					gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
 					gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTEvo))
 					gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, user))
+					a.setTenant(gc, XSTEvo)
 					gc.Next()
 					return
 				}
@@ -219,6 +279,7 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 					Name:   xServiceName,
 					Email:  fmt.Sprintf("%s@moladin.com", xServiceName),
 				}))
+				a.setTenant(gc, XSTSignature)
 				gc.Next()
 				return
 			}
@@ -235,6 +296,7 @@ func (a *MiddlewareAuthPackage) Auth(rbacPermissions []string) gin.HandlerFunc {
 					Name:   xServiceName,
 					Email:  fmt.Sprintf("%s@moladin.com", xServiceName),
 				}))
+				a.setTenant(gc, XSTAPIKey)
 				gc.Next()
 				return
 			}
@@ -258,7 +320,7 @@ func (a *MiddlewareAuthPackage) AuthToken() gin.HandlerFunc {
 
 		var token = gc.GetHeader(constant.AuthorizationHeader)
 		if token != "" {
-			user, err := a.MoladinEvoClient.UserDetail(gc.Request.Context(), token)
+			user, err := a.evoUserDetail(gc.Request.Context(), token)
 			if err != nil {
 				logger.Error(gc.Request.Context(), err.Error(), err, logger.Tag{
 					Key:   "logCtx",
@@ -281,6 +343,7 @@ func (a *MiddlewareAuthPackage) AuthToken() gin.HandlerFunc {
 
 			gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTEvo))
 			gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, user))
+			a.setTenant(gc, XSTEvo)
 			gc.Next()
 			return
 		}
@@ -316,6 +379,7 @@ func (a *MiddlewareAuthPackage) AuthXApiKey() gin.HandlerFunc {
 			Name:   serviceNameSender,
 			Email:  fmt.Sprintf("%s@moladin.com", serviceNameSender),
 		}))
+		a.setTenant(gc, XSTAPIKey)
 		gc.Next()
 	}
 }
@@ -372,6 +436,7 @@ func (a *MiddlewareAuthPackage) AuthSignature() gin.HandlerFunc {
 			Name:   serviceNameSender,
 			Email:  fmt.Sprintf("%s@moladin.com", serviceNameSender),
 		}))
+		a.setTenant(gc, XSTSignature)
 		gc.Next()
 	}
 }
@@ -396,7 +461,7 @@ func (a *MiddlewareAuthPackage) AuthRoleRBAC(allowedRoles []string) gin.HandlerF
 		tokenArr := strings.Split(authorizationToken, " ")
 		lastIndex := len(tokenArr) - 1
 		token := tokenArr[lastIndex]
-		isAllowed, userDetail, err := a.RBACClient.IsRoleAllowed(allowedRoles, token)
+		isAllowed, userDetail, err := a.isRoleAllowed(reqCtx, allowedRoles, token)
 		if err != nil {
 			logger.Error(gc.Request.Context(), err.Error(), err, logger.Tag{
 				Key:   "logCtx",
@@ -420,6 +485,7 @@ func (a *MiddlewareAuthPackage) AuthRoleRBAC(allowedRoles []string) gin.HandlerF
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTRBAC))
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, userDetail))
+		a.setTenant(gc, XSTRBAC)
 		gc.Next()
 	}
 }
@@ -444,7 +510,7 @@ func (a *MiddlewareAuthPackage) AuthPermissionRBAC(allowedPermissions []string)
 		tokenArr := strings.Split(authorizationToken, " ")
 		lastIndex := len(tokenArr) - 1
 		token := tokenArr[lastIndex]
-		isAllowed, userDetail, err := a.RBACClient.IsPermissionAllowed(allowedPermissions, token)
+		isAllowed, userDetail, err := a.isPermissionAllowed(reqCtx, allowedPermissions, token)
 		if err != nil || !isAllowed {
 			if err != nil {
 				logger.Error(gc.Request.Context(), err.Error(), err, logger.Tag{
@@ -462,6 +528,82 @@ func (a *MiddlewareAuthPackage) AuthPermissionRBAC(allowedPermissions []string)
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTRBAC))
 		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, userDetail))
+		a.setTenant(gc, XSTRBAC)
 		gc.Next()
 	}
 }
+
+// AuthJWT authenticate the bearer JWT with the JWTVerifier, without any
+// remote call. allowedPermissions are matched with the permissions claim,
+// empty allows every valid token.
+func (a *MiddlewareAuthPackage) AuthJWT(allowedPermissions []string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		const logCtx = "common.middleware.gin.auth.AuthJWT"
+
+		reqCtx := gc.Request.Context()
+		span := a.Sentry.StartSpan(reqCtx, logCtx)
+		defer span.Finish()
+
+		var authorizationToken = gc.GetHeader(constant.AuthorizationHeader)
+		token, isBearer := strings.CutPrefix(authorizationToken, "Bearer ")
+		if a.JWTVerifier == nil || !isBearer || !IsJWT(token) {
+			gc.AbortWithStatusJSON(http.StatusUnauthorized, responseModel.Response{
+				Status:  responseModel.StatusFail,
+				Message: http.StatusText(http.StatusUnauthorized)},
+			)
+			return
+		}
+
+		claims, userDetail, err := a.JWTVerifier.UserDetail(reqCtx, token)
+		if err != nil || !isJWTPermitted(claims, allowedPermissions) {
+			if err != nil {
+				logger.Warn(reqCtx, err.Error(), logger.Tag{
+					Key:   "logCtx",
+					Value: logCtx,
+				})
+			}
+			gc.AbortWithStatusJSON(http.StatusUnauthorized, responseModel.Response{
+				Status:  responseModel.StatusFail,
+				Message: http.StatusText(http.StatusUnauthorized),
+			})
+			return
+		}
+
+		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.AuthorizationHeader, authorizationToken))
+		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserType, XSTJWT))
+		gc.Request = gc.Request.WithContext(context.WithValue(gc.Request.Context(), constant.XUserDetail, userDetail))
+		a.setTenant(gc, XSTJWT)
+		gc.Next()
+	}
+}
+
+// isJWTPermitted report whether one of the permissions claim is allowed,
+// an empty allowedPermissions deny every token like RBAC does.
+func isJWTPermitted(claims JWTClaims, allowedPermissions []string) bool {
+	return containsAny(claims.Permissions, allowedPermissions)
+}
+
+// setTenant resolve the tenant of the user detail in the request context.
+func (a *MiddlewareAuthPackage) setTenant(gc *gin.Context, userType XUserType) {
+	if a.TenantResolver == nil {
+		return
+	}
+
+	reqCtx := gc.Request.Context()
+	tenantID, ok := a.TenantResolver(reqCtx, userType, reqCtx.Value(constant.XUserDetail))
+	if !ok {
+		return
+	}
+
+	gc.Request = gc.Request.WithContext(commonContext.WithTenant(reqCtx, tenantID))
+}
+
+func containsAny(values, allowed []string) bool {
+	for _, value := range values {
+		if slices.Contains(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
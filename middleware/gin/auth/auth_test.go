@@ -12,6 +12,7 @@ import (
 	"bitbucket.org/moladinTech/go-lib-common/cast"
 	moladinEvoMock "bitbucket.org/moladinTech/go-lib-common/client/moladin_evo/mocks"
 	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonContext "bitbucket.org/moladinTech/go-lib-common/context"
 	"bitbucket.org/moladinTech/go-lib-common/middleware/gin/auth"
 	sentryMock "bitbucket.org/moladinTech/go-lib-common/sentry/mocks"
 	"bitbucket.org/moladinTech/go-lib-common/signature"
@@ -137,6 +138,59 @@ func TestAuthToken_ShouldSucceedOnRoleAuthorized(t *testing.T) {
 	})
 }
 
+func TestAuthToken_ShouldSetTenant(t *testing.T) {
+	t.Run("Should set tenant from user detail", func(t *testing.T) {
+		span := sentry.Span{}
+		token := "Bearer token"
+		sentry := sentryMock.NewISentry(t)
+		sentry.On("StartSpan", mock.Anything, mock.Anything).
+			Return(&span).
+			Once()
+
+		moladinEvo := moladinEvoMock.NewIMoladinEvo(t)
+		moladinEvo.On("UserDetail", mock.Anything, token).
+			Return(model.UserDetail{
+				UserId: 1,
+				Name:   "John",
+				Email:  "john@example.com",
+				Role: model.UserRole{
+					Id:   7,
+					Name: "finance",
+				},
+			}, nil).
+			Once()
+		auth := auth.NewAuth(validator.New(),
+			auth.WithSentry(sentry),
+			auth.WithPermittedRoles([]string{"finance"}),
+			auth.WithMoladinEvoClient(moladinEvo),
+			auth.WithTenantResolver(func(ctx context.Context, userType auth.XUserType, userDetail any) (any, bool) {
+				user, ok := userDetail.(model.UserDetail)
+				if !ok || userType != auth.XSTEvo {
+					return nil, false
+				}
+				return user.Role.Id, true
+			}),
+		)
+		require.NotNil(t, auth)
+
+		var tenantID any
+		gin.SetMode(gin.TestMode)
+		r := gin.Default()
+		r.GET("/", auth.AuthToken(), func(c *gin.Context) {
+			tenantID, _ = commonContext.GetTenant(c.Request.Context())
+		})
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header = http.Header{}
+		req.Header.Set(constant.AuthorizationHeader, token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, int64(7), tenantID)
+	})
+}
+
 func TestAuthToken_ErrorOnUnauthorizedRole(t *testing.T) {
 	t.Run("Error on Unauthorized Role", func(t *testing.T) {
 		span := sentry.Span{}
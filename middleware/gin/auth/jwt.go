@@ -0,0 +1,581 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-activity-log/model"
+	commonTime "bitbucket.org/moladinTech/go-lib-common/time"
+	commonValidator "bitbucket.org/moladinTech/go-lib-common/validator"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgHS256 = "HS256"
+
+	defaultJWKSCacheTTL           = time.Hour
+	defaultJWKSMinRefresh         = time.Minute
+	defaultJWKSFetchTimeout       = 10 * time.Second
+	defaultJWTLeeway              = 30 * time.Second
+	jwtSegments                   = 3
+	jwksMaxBodySize         int64 = 1 << 20
+	minRSAKeyBits                 = 2048
+)
+
+var (
+	ErrInvalidJWT  = errors.New("invalid jwt")
+	ErrJWTExpired  = errors.New("jwt is expired")
+	ErrJWTUnknown  = errors.New("jwt key is unknown")
+	ErrJWKSFetch   = errors.New("failed to fetch jwks")
+	ErrJWTAudience = errors.New("jwt audience is not accepted")
+	ErrJWTIssuer   = errors.New("jwt issuer is not accepted")
+)
+
+// JWTClaims is the payload of a verified token, Raw has every claim
+// including the ones not mapped to the fields.
+type JWTClaims struct {
+	Issuer      string
+	Subject     string
+	Audience    []string
+	ExpiresAt   time.Time
+	NotBefore   time.Time
+	IssuedAt    time.Time
+	Name        string
+	Email       string
+	Role        string
+	Permissions []string
+	Raw         map[string]any
+}
+
+// ClaimsMapper map the verified claims to the user detail put in the
+// request context as constant.XUserDetail.
+type ClaimsMapper func(claims JWTClaims) (userDetail any, err error)
+
+// JWTVerifier verify the bearer JWTs locally, the public keys are read
+// from a JWKS url or file and cached, HS256 tokens are verified with
+// HMACSecret. Only the Algorithms are accepted, RS256 and ES256 with a
+// JWKS, HS256 otherwise.
+type JWTVerifier struct {
+	JWKSURL      string `validate:"required_without_all=JWKSFile HMACSecret"`
+	JWKSFile     string
+	HMACSecret   []byte
+	Algorithms   []string `validate:"dive,oneof=RS256 ES256 HS256"`
+	Issuer       string
+	Audiences    []string
+	Leeway       time.Duration
+	CacheTTL     time.Duration
+	HTTPClient   *http.Client
+	ClaimsMapper ClaimsMapper
+	Time         commonTime.TimeItf
+
+	mu        *sync.RWMutex
+	fetchMu   *sync.Mutex
+	keys      map[string]jwk
+	fetchedAt time.Time
+	expiresAt time.Time
+	fetchErr  error
+}
+
+type JWTOption func(*JWTVerifier)
+
+// WithJWKSURL fetch the public keys from url, e.g.
+// https://auth.moladin.com/.well-known/jwks.json.
+func WithJWKSURL(url string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.JWKSURL = url
+	}
+}
+
+// WithJWKSFile read the public keys from the JWKS file at path.
+func WithJWKSFile(path string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.JWKSFile = path
+	}
+}
+
+// WithHMACSecret set the secret of the HS256 tokens, they are accepted by
+// default only without JWKS, see WithAlgorithms.
+func WithHMACSecret(secret []byte) JWTOption {
+	return func(v *JWTVerifier) {
+		v.HMACSecret = secret
+	}
+}
+
+// WithAlgorithms set the accepted algorithms among AlgRS256, AlgES256 and
+// AlgHS256, e.g. to accept both JWKS and HS256 tokens.
+func WithAlgorithms(algorithms ...string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.Algorithms = algorithms
+	}
+}
+
+// WithIssuer accept only the tokens with the iss claim equal to issuer.
+func WithIssuer(issuer string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.Issuer = issuer
+	}
+}
+
+// WithAudiences accept only the tokens with one of audiences in the aud
+// claim.
+func WithAudiences(audiences ...string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.Audiences = audiences
+	}
+}
+
+// WithLeeway set the clock skew allowed on exp, nbf and iat, default 30s.
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(v *JWTVerifier) {
+		v.Leeway = leeway
+	}
+}
+
+// WithJWKSCacheTTL set how long the keys are cached, default 1h. An
+// unknown kid refresh the keys at most once a minute.
+func WithJWKSCacheTTL(ttl time.Duration) JWTOption {
+	return func(v *JWTVerifier) {
+		v.CacheTTL = ttl
+	}
+}
+
+func WithJWKSHTTPClient(client *http.Client) JWTOption {
+	return func(v *JWTVerifier) {
+		v.HTTPClient = client
+	}
+}
+
+// WithClaimsMapper set the mapper of the claims, default DefaultClaimsMapper.
+func WithClaimsMapper(mapper ClaimsMapper) JWTOption {
+	return func(v *JWTVerifier) {
+		v.ClaimsMapper = mapper
+	}
+}
+
+func WithJWTTime(time commonTime.TimeItf) JWTOption {
+	return func(v *JWTVerifier) {
+		v.Time = time
+	}
+}
+
+func NewJWTVerifier(
+	validator *validator.Validate,
+	options ...JWTOption,
+) *JWTVerifier {
+	jwtVerifier := &JWTVerifier{
+		Leeway:       defaultJWTLeeway,
+		CacheTTL:     defaultJWKSCacheTTL,
+		HTTPClient:   &http.Client{Timeout: defaultJWKSFetchTimeout},
+		ClaimsMapper: DefaultClaimsMapper,
+		Time:         commonTime.InitTime(),
+		mu:           &sync.RWMutex{},
+		fetchMu:      &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(jwtVerifier)
+	}
+
+	if len(jwtVerifier.Algorithms) == 0 {
+		jwtVerifier.Algorithms = []string{AlgHS256}
+		if jwtVerifier.JWKSURL != "" || jwtVerifier.JWKSFile != "" {
+			jwtVerifier.Algorithms = []string{AlgRS256, AlgES256}
+		}
+	}
+
+	err := validator.Struct(jwtVerifier)
+	if err != nil {
+		panic(commonValidator.ToErrResponse(err))
+	}
+
+	return jwtVerifier
+}
+
+// DefaultClaimsMapper map the claims to model.UserDetail of
+// go-lib-activity-log: the user id is the numeric sub or user_id claim,
+// the role is the role claim.
+func DefaultClaimsMapper(claims JWTClaims) (any, error) {
+	userDetail := model.UserDetail{
+		Name:  claims.Name,
+		Email: claims.Email,
+		Role:  model.UserRole{Name: claims.Role},
+	}
+
+	switch userID := claims.Raw["user_id"].(type) {
+	case float64:
+		userDetail.UserId = int64(userID)
+	case string:
+		userDetail.UserId, _ = strconv.ParseInt(userID, 10, 64)
+	default:
+		userDetail.UserId, _ = strconv.ParseInt(claims.Subject, 10, 64)
+	}
+
+	return userDetail, nil
+}
+
+// IsJWT report whether token, without the Bearer prefix, looks like a JWT.
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == jwtSegments-1
+}
+
+// Verify check the signature, exp, nbf, iat, iss and aud of token, without
+// the Bearer prefix, and return its claims. The keys are fetched detached
+// from ctx so a cancelled request doesn't fail the others.
+func (v *JWTVerifier) Verify(_ context.Context, token string) (JWTClaims, error) {
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods(v.Algorithms),
+		jwt.WithLeeway(v.Leeway),
+		jwt.WithTimeFunc(v.Time.Now),
+		jwt.WithIssuedAt(),
+	}
+	if v.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(v.Issuer))
+	}
+
+	raw := jwt.MapClaims{}
+	if _, err := jwt.NewParser(parserOptions...).ParseWithClaims(token, raw, v.keyFunc); err != nil {
+		return JWTClaims{}, toJWTError(err)
+	}
+
+	claims := toClaims(raw)
+	if claims.ExpiresAt.IsZero() {
+		return JWTClaims{}, fmt.Errorf("%w: exp is required", ErrJWTExpired)
+	}
+	// jwt.WithAudience of v5.2 accept a single audience
+	if len(v.Audiences) > 0 && !containsAny(claims.Audience, v.Audiences) {
+		return JWTClaims{}, ErrJWTAudience
+	}
+
+	return claims, nil
+}
+
+// UserDetail verify token and map its claims with the ClaimsMapper.
+func (v *JWTVerifier) UserDetail(ctx context.Context, token string) (JWTClaims, any, error) {
+	claims, err := v.Verify(ctx, token)
+	if err != nil {
+		return JWTClaims{}, nil, err
+	}
+
+	userDetail, err := v.ClaimsMapper(claims)
+	if err != nil {
+		return JWTClaims{}, nil, err
+	}
+
+	return claims, userDetail, nil
+}
+
+// keyFunc return the key verifying token, the alg of the token must match
+// the type of the key.
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+	if alg == AlgHS256 {
+		if len(v.HMACSecret) == 0 {
+			return nil, fmt.Errorf("%w: no hmac secret", ErrInvalidJWT)
+		}
+		return v.HMACSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, err := v.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if !key.verifies(alg) {
+		return nil, fmt.Errorf("%w: alg %s doesn't match the key", ErrInvalidJWT, alg)
+	}
+
+	return key.publicKey, nil
+}
+
+// toJWTError map the errors of the parser to the errors of the verifier.
+func toJWTError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidJWT), errors.Is(err, ErrJWTUnknown), errors.Is(err, ErrJWKSFetch):
+		return err
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return fmt.Errorf("%w: %w", ErrJWTExpired, err)
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return fmt.Errorf("%w: %w", ErrJWTIssuer, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidJWT, err)
+	}
+}
+
+type jwk struct {
+	alg       string
+	publicKey crypto.PublicKey
+}
+
+// verifies report whether the key verifies the tokens signed with alg, the
+// alg is tied to the type of the key.
+func (k jwk) verifies(alg string) bool {
+	if k.alg != "" && k.alg != alg {
+		return false
+	}
+
+	switch publicKey := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256 && publicKey.Curve == elliptic.P256()
+	default:
+		return false
+	}
+}
+
+// key return the cached key of kid, the keys are refreshed when the cache
+// is expired or, at most once a minute, when kid is unknown. The keys are
+// fetched without holding mu, the stale key is served meanwhile.
+func (v *JWTVerifier) key(kid string) (jwk, error) {
+	now := v.Time.Now()
+
+	v.mu.RLock()
+	key, ok := v.lookup(kid)
+	fetchedAt, expiresAt, fetchErr := v.fetchedAt, v.expiresAt, v.fetchErr
+	v.mu.RUnlock()
+
+	isExpired := !now.Before(expiresAt)
+	if ok && !isExpired {
+		return key, nil
+	}
+	if !ok && !isExpired && now.Sub(fetchedAt) < defaultJWKSMinRefresh {
+		return jwk{}, unknownKeyError(kid, fetchErr)
+	}
+
+	if ok {
+		if !v.fetchMu.TryLock() {
+			// another request is refreshing the keys
+			return key, nil
+		}
+	} else {
+		v.fetchMu.Lock()
+	}
+	defer v.fetchMu.Unlock()
+
+	v.mu.RLock()
+	isRefreshed := !v.fetchedAt.Equal(fetchedAt)
+	v.mu.RUnlock()
+
+	if !isRefreshed {
+		keys, err := v.fetchKeys()
+
+		v.mu.Lock()
+		v.fetchedAt, v.fetchErr = v.Time.Now(), err
+		if err != nil {
+			// retry after defaultJWKSMinRefresh, the stale keys are kept
+			v.expiresAt = v.fetchedAt.Add(defaultJWKSMinRefresh)
+		} else {
+			v.keys, v.expiresAt = keys, v.fetchedAt.Add(v.CacheTTL)
+		}
+		v.mu.Unlock()
+	}
+
+	v.mu.RLock()
+	key, ok = v.lookup(kid)
+	fetchErr = v.fetchErr
+	v.mu.RUnlock()
+
+	if !ok {
+		return jwk{}, unknownKeyError(kid, fetchErr)
+	}
+
+	return key, nil
+}
+
+func unknownKeyError(kid string, fetchErr error) error {
+	if fetchErr != nil {
+		return fmt.Errorf("%w: kid %q: %w", ErrJWTUnknown, kid, fetchErr)
+	}
+
+	return fmt.Errorf("%w: kid %q", ErrJWTUnknown, kid)
+}
+
+// lookup return the key of kid, the only key when the token has no kid.
+func (v *JWTVerifier) lookup(kid string) (jwk, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *JWTVerifier) fetchKeys() (map[string]jwk, error) {
+	var (
+		body []byte
+		err  error
+	)
+
+	switch {
+	case v.JWKSURL != "":
+		body, err = v.fetchURL()
+	case v.JWKSFile != "":
+		body, err = os.ReadFile(v.JWKSFile)
+	default:
+		return nil, fmt.Errorf("%w: no jwks source", ErrJWKSFetch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWKSFetch, err)
+	}
+
+	return parseJWKS(body)
+}
+
+// fetchURL get the JWKS with its own timeout, detached from the request
+// which triggered the refresh.
+func (v *JWTVerifier) fetchURL() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultJWKSFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, jwksMaxBodySize))
+}
+
+// parseJWKS parse the RSA, of at least 2048 bits, and P-256 EC signing keys
+// of the JWKS, the other keys are skipped.
+func parseJWKS(body []byte) (map[string]jwk, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &jwks); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWKSFetch, err)
+	}
+
+	keys := make(map[string]jwk, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				continue
+			}
+			publicKey := &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+			if publicKey.N.BitLen() < minRSAKeyBits {
+				continue
+			}
+			keys[key.Kid] = jwk{alg: key.Alg, publicKey: publicKey}
+		case "EC":
+			if key.Crv != "P-256" {
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(key.X)
+			y, errY := base64.RawURLEncoding.DecodeString(key.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			publicKey := &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+				continue
+			}
+			keys[key.Kid] = jwk{alg: key.Alg, publicKey: publicKey}
+		}
+	}
+
+	return keys, nil
+}
+
+func toClaims(raw map[string]any) JWTClaims {
+	return JWTClaims{
+		Issuer:      stringClaim(raw, "iss"),
+		Subject:     stringClaim(raw, "sub"),
+		Audience:    stringsClaim(raw, "aud"),
+		ExpiresAt:   timeClaim(raw, "exp"),
+		NotBefore:   timeClaim(raw, "nbf"),
+		IssuedAt:    timeClaim(raw, "iat"),
+		Name:        stringClaim(raw, "name"),
+		Email:       stringClaim(raw, "email"),
+		Role:        stringClaim(raw, "role"),
+		Permissions: stringsClaim(raw, "permissions"),
+		Raw:         raw,
+	}
+}
+
+func stringClaim(raw map[string]any, key string) string {
+	value, _ := raw[key].(string)
+	return value
+}
+
+// stringsClaim return the claim being a string or an array of strings.
+func stringsClaim(raw map[string]any, key string) []string {
+	switch value := raw[key].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func timeClaim(raw map[string]any, key string) time.Time {
+	value, ok := raw[key].(float64)
+	if !ok {
+		return time.Time{}
+	}
+
+	seconds := int64(value)
+	return time.Unix(seconds, int64((value-float64(seconds))*float64(time.Second)))
+}
@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/moladinTech/go-lib-activity-log/model"
+	"bitbucket.org/moladinTech/go-lib-common/cache"
+	moladinEvoClient "bitbucket.org/moladinTech/go-lib-common/client/moladin_evo"
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	commonValidator "bitbucket.org/moladinTech/go-lib-common/validator"
+	rbacModel "bitbucket.org/moladinTech/go-lib-rbac/model"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultUserCacheTTL         = time.Minute
+	defaultUserCacheNegativeTTL = 10 * time.Second
+	defaultUserCacheKeyPrefix   = "auth:user:"
+)
+
+// ErrCachedInvalidToken is returned while the token rejected by RBAC or
+// moladin evo is negatively cached.
+var ErrCachedInvalidToken = errors.New("token is cached as invalid")
+
+// UserCache cache the user detail resolved by RBAC and moladin evo, keyed
+// by the sha256 of the token so the tokens are never stored.
+type UserCache struct {
+	Cacher      cache.Cacher `validate:"required"`
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	KeyPrefix   string
+	// IsNegative report whether the lookup error means the token is
+	// invalid, the other errors, e.g. outages, are not cached.
+	IsNegative func(err error) bool
+}
+
+type UserCacheOption func(*UserCache)
+
+func WithUserCacher(cacher cache.Cacher) UserCacheOption {
+	return func(c *UserCache) {
+		c.Cacher = cacher
+	}
+}
+
+// WithUserCacheTTL set how long the user detail is cached, default 1m.
+// Keep it short, the roles and permissions changes are seen after it.
+func WithUserCacheTTL(ttl time.Duration) UserCacheOption {
+	return func(c *UserCache) {
+		c.TTL = ttl
+	}
+}
+
+// WithUserCacheNegativeTTL set how long the invalid tokens are cached,
+// default 10s, 0 disable the negative caching.
+func WithUserCacheNegativeTTL(ttl time.Duration) UserCacheOption {
+	return func(c *UserCache) {
+		c.NegativeTTL = ttl
+	}
+}
+
+// WithUserCacheKeyPrefix set the prefix of the keys, default auth:user:.
+func WithUserCacheKeyPrefix(prefix string) UserCacheOption {
+	return func(c *UserCache) {
+		c.KeyPrefix = prefix
+	}
+}
+
+// WithUserCacheNegativeFilter set which lookup errors are negatively
+// cached, by default only the explicit rejections of the token, see
+// IsInvalidTokenErr.
+func WithUserCacheNegativeFilter(isNegative func(err error) bool) UserCacheOption {
+	return func(c *UserCache) {
+		c.IsNegative = isNegative
+	}
+}
+
+func NewUserCache(
+	validator *validator.Validate,
+	options ...UserCacheOption,
+) *UserCache {
+	userCache := &UserCache{
+		TTL:         defaultUserCacheTTL,
+		NegativeTTL: defaultUserCacheNegativeTTL,
+		KeyPrefix:   defaultUserCacheKeyPrefix,
+		IsNegative:  IsInvalidTokenErr,
+	}
+
+	for _, option := range options {
+		option(userCache)
+	}
+
+	err := validator.Struct(userCache)
+	if err != nil {
+		panic(commonValidator.ToErrResponse(err))
+	}
+
+	return userCache
+}
+
+// IsInvalidTokenErr report whether err is an explicit rejection of the
+// token: moladin evo ErrInvalidToken or an error with a 401 or 403
+// StatusCode. Set WithUserCacheNegativeFilter to match the RBAC errors.
+func IsInvalidTokenErr(err error) bool {
+	if errors.Is(err, moladinEvoClient.ErrInvalidToken) {
+		return true
+	}
+
+	var statusCoder interface{ StatusCode() int }
+	if errors.As(err, &statusCoder) {
+		statusCode := statusCoder.StatusCode()
+		return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+	}
+
+	return false
+}
+
+type cachedUser struct {
+	Invalid bool              `json:"invalid"`
+	RBAC    *rbacModel.Me     `json:"rbac,omitempty"`
+	Evo     *model.UserDetail `json:"evo,omitempty"`
+	// Decisions is the answer of RBAC per allowed roles or permissions,
+	// see decisionKey. They expire with the first one, at ExpiresAt.
+	Decisions map[string]bool `json:"decisions,omitempty"`
+	ExpiresAt time.Time       `json:"expiresAt,omitempty"`
+}
+
+// Invalidate delete the cached user detail of token, with or without the
+// Bearer prefix, e.g. on logout.
+func (c *UserCache) Invalidate(ctx context.Context, token string) error {
+	for _, userType := range []XUserType{XSTRBAC, XSTEvo} {
+		if err := c.Cacher.Delete(ctx, c.key(userType, token)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateUser delete the cached user detail of every token of the user
+// with email, e.g. when the roles of the user change. The tokens are
+// indexed on a best effort basis, a token cached concurrently may be kept
+// until its TTL.
+func (c *UserCache) InvalidateUser(ctx context.Context, email string) error {
+	indexKey := c.indexKey(email)
+
+	var keys []string
+	if err := c.Cacher.Get(ctx, indexKey, &keys); err != nil {
+		if isCacheMiss(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, key := range keys {
+		if err := c.Cacher.Delete(ctx, cache.Key(key)); err != nil {
+			return err
+		}
+	}
+
+	return c.Cacher.Delete(ctx, indexKey)
+}
+
+func (c *UserCache) key(userType XUserType, token string) cache.Key {
+	hash := sha256.Sum256([]byte(strings.TrimPrefix(token, "Bearer ")))
+	return cache.Key(c.KeyPrefix + string(userType) + ":" + hex.EncodeToString(hash[:]))
+}
+
+func (c *UserCache) indexKey(email string) cache.Key {
+	return cache.Key(c.KeyPrefix + "index:" + strings.ToLower(email))
+}
+
+// get return the cached user of token, ok is false on a miss.
+func (c *UserCache) get(ctx context.Context, userType XUserType, token string) (user cachedUser, ok bool) {
+	const logCtx = "common.middleware.gin.auth.UserCache.get"
+
+	err := c.Cacher.Get(ctx, c.key(userType, token), &user)
+	if err != nil {
+		if !isCacheMiss(err) {
+			logger.Warn(ctx, err.Error(), logger.Tag{Key: "logCtx", Value: logCtx})
+		}
+		return cachedUser{}, false
+	}
+
+	return user, user.Invalid || user.RBAC != nil || user.Evo != nil
+}
+
+// set cache the user resolved for token, or the token as invalid when
+// err is negative.
+func (c *UserCache) set(ctx context.Context, userType XUserType, token string, user cachedUser, email string, err error) {
+	const logCtx = "common.middleware.gin.auth.UserCache.set"
+
+	ttl := c.TTL
+	if err != nil {
+		if c.NegativeTTL <= 0 || !c.IsNegative(err) {
+			return
+		}
+		user, ttl = cachedUser{Invalid: true}, c.NegativeTTL
+	} else if !user.ExpiresAt.IsZero() {
+		if ttl = time.Until(user.ExpiresAt); ttl <= 0 {
+			return
+		}
+	}
+
+	key := c.key(userType, token)
+	if errSet := c.Cacher.Set(ctx, cache.Data{Key: key, Value: user}, ttl); errSet != nil {
+		logger.Warn(ctx, errSet.Error(), logger.Tag{Key: "logCtx", Value: logCtx})
+		return
+	}
+
+	if !user.Invalid && email != "" {
+		c.index(ctx, email, key)
+	}
+}
+
+// index add key to the keys of the user deleted by InvalidateUser.
+func (c *UserCache) index(ctx context.Context, email string, key cache.Key) {
+	const logCtx = "common.middleware.gin.auth.UserCache.index"
+
+	var (
+		indexKey = c.indexKey(email)
+		keys     []string
+	)
+	if err := c.Cacher.Get(ctx, indexKey, &keys); err != nil && !isCacheMiss(err) {
+		logger.Warn(ctx, err.Error(), logger.Tag{Key: "logCtx", Value: logCtx})
+		return
+	}
+	if slices.Contains(keys, string(key)) {
+		return
+	}
+
+	keys = append(keys, string(key))
+	if err := c.Cacher.Set(ctx, cache.Data{Key: indexKey, Value: keys}, c.TTL); err != nil {
+		logger.Warn(ctx, err.Error(), logger.Tag{Key: "logCtx", Value: logCtx})
+	}
+}
+
+func isCacheMiss(err error) bool {
+	return errors.Is(err, redis.Nil) ||
+		errors.Is(err, cache.ErrInMemNotFound) ||
+		errors.Is(err, cache.ErrInMemExpired)
+}
+
+// isPermissionAllowed call RBACClient.IsPermissionAllowed, the decision is
+// cached per allowed permissions when the UserCache is set.
+func (a *MiddlewareAuthPackage) isPermissionAllowed(ctx context.Context, allowedPermissions []string, token string) (bool, *rbacModel.Me, error) {
+	if a.UserCache == nil {
+		return a.RBACClient.IsPermissionAllowed(allowedPermissions, token)
+	}
+
+	return a.cachedRBACDecision(ctx, decisionKey("permission", allowedPermissions), token, func() (bool, *rbacModel.Me, error) {
+		return a.RBACClient.IsPermissionAllowed(allowedPermissions, token)
+	})
+}
+
+// isRoleAllowed call RBACClient.IsRoleAllowed, the decision is cached per
+// allowed roles when the UserCache is set.
+func (a *MiddlewareAuthPackage) isRoleAllowed(ctx context.Context, allowedRoles []string, token string) (bool, *rbacModel.Me, error) {
+	if a.UserCache == nil {
+		return a.RBACClient.IsRoleAllowed(allowedRoles, token)
+	}
+
+	return a.cachedRBACDecision(ctx, decisionKey("role", allowedRoles), token, func() (bool, *rbacModel.Me, error) {
+		return a.RBACClient.IsRoleAllowed(allowedRoles, token)
+	})
+}
+
+// cachedRBACDecision return the cached decision of key, or call isAllowed
+// and cache its decision along the user detail. The matching is left to
+// go-lib-rbac.
+func (a *MiddlewareAuthPackage) cachedRBACDecision(
+	ctx context.Context,
+	key string,
+	token string,
+	isAllowed func() (bool, *rbacModel.Me, error),
+) (bool, *rbacModel.Me, error) {
+	cached, ok := a.UserCache.get(ctx, XSTRBAC, token)
+	if ok {
+		if cached.Invalid {
+			return false, nil, ErrCachedInvalidToken
+		}
+		if decision, ok := cached.Decisions[key]; ok && cached.RBAC != nil {
+			return decision, cached.RBAC, nil
+		}
+	}
+
+	decision, userDetail, err := isAllowed()
+	if err != nil {
+		a.UserCache.set(ctx, XSTRBAC, token, cachedUser{}, "", err)
+		return decision, userDetail, err
+	}
+	if userDetail == nil {
+		return decision, userDetail, err
+	}
+
+	// the decisions of the other keys are kept until the entry expires, a
+	// concurrent request may overwrite them which only cost another call
+	entry := cachedUser{
+		RBAC:      userDetail,
+		Decisions: map[string]bool{key: decision},
+		ExpiresAt: time.Now().Add(a.UserCache.TTL),
+	}
+	if ok && !cached.ExpiresAt.IsZero() {
+		entry.ExpiresAt = cached.ExpiresAt
+		for cachedKey, cachedDecision := range cached.Decisions {
+			entry.Decisions[cachedKey] = cachedDecision
+		}
+		entry.Decisions[key] = decision
+	}
+
+	a.UserCache.set(ctx, XSTRBAC, token, entry, userDetail.Email, nil)
+
+	return decision, userDetail, nil
+}
+
+// decisionKey identify the allowed values regardless of their order.
+func decisionKey(kind string, allowed []string) string {
+	sorted := append([]string(nil), allowed...)
+	sort.Strings(sorted)
+
+	return kind + ":" + strings.Join(sorted, ",")
+}
+
+// evoUserDetail call MoladinEvoClient.UserDetail, the user detail is read
+// from the UserCache when set.
+func (a *MiddlewareAuthPackage) evoUserDetail(ctx context.Context, token string) (model.UserDetail, error) {
+	if a.UserCache == nil {
+		return a.MoladinEvoClient.UserDetail(ctx, token)
+	}
+
+	if cached, ok := a.UserCache.get(ctx, XSTEvo, token); ok {
+		if cached.Invalid || cached.Evo == nil {
+			return model.UserDetail{}, ErrCachedInvalidToken
+		}
+		return *cached.Evo, nil
+	}
+
+	userDetail, err := a.MoladinEvoClient.UserDetail(ctx, token)
+	a.UserCache.set(ctx, XSTEvo, token, cachedUser{Evo: &userDetail}, userDetail.Email, err)
+
+	return userDetail, err
+}
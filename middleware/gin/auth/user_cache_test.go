@@ -0,0 +1,306 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/moladinTech/go-lib-activity-log/model"
+	"bitbucket.org/moladinTech/go-lib-common/cache"
+	moladinEvo "bitbucket.org/moladinTech/go-lib-common/client/moladin_evo"
+	moladinEvoMock "bitbucket.org/moladinTech/go-lib-common/client/moladin_evo/mocks"
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	"bitbucket.org/moladinTech/go-lib-common/middleware/gin/auth"
+	sentryMock "bitbucket.org/moladinTech/go-lib-common/sentry/mocks"
+	"bitbucket.org/moladinTech/go-lib-common/validator"
+	rbacModel "bitbucket.org/moladinTech/go-lib-rbac/model"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRBAC struct {
+	me    *rbacModel.Me
+	err   error
+	calls int
+}
+
+// statusError is an RBAC error carrying the status code of the response.
+type statusError struct {
+	statusCode int
+}
+
+func (e statusError) Error() string { return http.StatusText(e.statusCode) }
+
+func (e statusError) StatusCode() int { return e.statusCode }
+
+func (f *fakeRBAC) IsRoleAllowed(allowedRoles []string, token string) (bool, *rbacModel.Me, error) {
+	f.calls++
+	if f.err != nil {
+		return false, nil, f.err
+	}
+	for _, role := range f.me.Roles {
+		for _, allowed := range allowedRoles {
+			if role == allowed {
+				return true, f.me, nil
+			}
+		}
+	}
+	return false, f.me, nil
+}
+
+// IsPermissionAllowed match the permissions ending with .* as a prefix, a
+// rule the cache can't reproduce from the user detail.
+func (f *fakeRBAC) IsPermissionAllowed(allowedPermissions []string, token string) (bool, *rbacModel.Me, error) {
+	f.calls++
+	if f.err != nil {
+		return false, nil, f.err
+	}
+	for _, permission := range f.me.Permissions {
+		for _, allowed := range allowedPermissions {
+			prefix, isWildcard := strings.CutSuffix(permission, "*")
+			if permission == allowed || isWildcard && strings.HasPrefix(allowed, prefix) {
+				return true, f.me, nil
+			}
+		}
+	}
+	return false, f.me, nil
+}
+
+func (f *fakeRBAC) GetUserDetail(token string) (*rbacModel.Me, error) {
+	f.calls++
+	return f.me, f.err
+}
+
+func newSentryMock(t *testing.T) *sentryMock.ISentry {
+	span := sentry.Span{}
+	sentry := sentryMock.NewISentry(t)
+	sentry.On("StartSpan", mock.Anything, mock.Anything).
+		Return(&span)
+
+	return sentry
+}
+
+func serveAuth(handler gin.HandlerFunc, token string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", handler, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(constant.AuthorizationHeader, token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestUserCache_Evo(t *testing.T) {
+	const token = "Bearer token"
+	user := model.UserDetail{
+		UserId: 1,
+		Name:   "John",
+		Email:  "john@example.com",
+		Role:   model.UserRole{Id: 1, Name: "finance"},
+	}
+
+	type (
+		args struct {
+			user       model.UserDetail
+			err        error
+			invalidate func(ctx context.Context, userCache *auth.UserCache) error
+		}
+		want struct {
+			code  int
+			calls int
+		}
+		testCase struct {
+			name string
+			args args
+			want want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "valid token is cached",
+			args: args{user: user},
+			want: want{code: http.StatusOK, calls: 1},
+		},
+		{
+			name: "invalid token is negatively cached",
+			args: args{err: fmt.Errorf("%w: token is expired", moladinEvo.ErrInvalidToken)},
+			want: want{code: http.StatusUnauthorized, calls: 1},
+		},
+		{
+			name: "timeout is not cached",
+			args: args{err: context.DeadlineExceeded},
+			want: want{code: http.StatusUnauthorized, calls: 2},
+		},
+		{
+			name: "upstream error is not cached",
+			args: args{err: errors.New("bad gateway")},
+			want: want{code: http.StatusUnauthorized, calls: 2},
+		},
+		{
+			name: "invalidated token is looked up again",
+			args: args{
+				user: user,
+				invalidate: func(ctx context.Context, userCache *auth.UserCache) error {
+					return userCache.Invalidate(ctx, token)
+				},
+			},
+			want: want{code: http.StatusOK, calls: 2},
+		},
+		{
+			name: "invalidated user is looked up again",
+			args: args{
+				user: user,
+				invalidate: func(ctx context.Context, userCache *auth.UserCache) error {
+					return userCache.InvalidateUser(ctx, "john@example.com")
+				},
+			},
+			want: want{code: http.StatusOK, calls: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			moladinEvoClient := moladinEvoMock.NewIMoladinEvo(t)
+			moladinEvoClient.On("UserDetail", mock.Anything, token).
+				Return(tc.args.user, tc.args.err).
+				Times(tc.want.calls)
+
+			userCache := auth.NewUserCache(validator.New(), auth.WithUserCacher(cache.NewInMemory()))
+			middleware := auth.NewAuth(validator.New(),
+				auth.WithSentry(newSentryMock(t)),
+				auth.WithMoladinEvoClient(moladinEvoClient),
+				auth.WithPermittedRoles([]string{"finance"}),
+				auth.WithUserCache(userCache),
+			)
+
+			require.Equal(t, tc.want.code, serveAuth(middleware.AuthToken(), token))
+			if tc.args.invalidate != nil {
+				require.NoError(t, tc.args.invalidate(context.Background(), userCache))
+			}
+			require.Equal(t, tc.want.code, serveAuth(middleware.AuthToken(), token))
+		})
+	}
+}
+
+func TestUserCache_RBAC(t *testing.T) {
+	const token = "Bearer 5f1c0c4e-4b1d-4f5e-9a8e-0e1a6c7d8b9f"
+
+	type (
+		request struct {
+			handler func(middleware auth.IMiddlewareAuth) gin.HandlerFunc
+			code    int
+		}
+		args struct {
+			err      error
+			requests []request
+		}
+		want struct {
+			calls int
+		}
+		testCase struct {
+			name string
+			args args
+			want want
+		}
+	)
+
+	permission := func(permissions ...string) func(middleware auth.IMiddlewareAuth) gin.HandlerFunc {
+		return func(middleware auth.IMiddlewareAuth) gin.HandlerFunc {
+			return middleware.AuthPermissionRBAC(permissions)
+		}
+	}
+	role := func(roles ...string) func(middleware auth.IMiddlewareAuth) gin.HandlerFunc {
+		return func(middleware auth.IMiddlewareAuth) gin.HandlerFunc {
+			return middleware.AuthRoleRBAC(roles)
+		}
+	}
+
+	testCases := []testCase{
+		{
+			name: "decision is cached per permissions as decided by RBAC",
+			args: args{requests: []request{
+				{handler: permission("loan.read"), code: http.StatusOK},
+				{handler: permission("loan.read"), code: http.StatusOK},
+				{handler: permission("loan.approve", "loan.read"), code: http.StatusOK},
+				{handler: permission("loan.read", "loan.approve"), code: http.StatusOK},
+				{handler: permission("user.write"), code: http.StatusUnauthorized},
+				{handler: permission("user.write"), code: http.StatusUnauthorized},
+			}},
+			want: want{calls: 3},
+		},
+		{
+			name: "decision is cached per roles",
+			args: args{requests: []request{
+				{handler: role("admin"), code: http.StatusOK},
+				{handler: role("admin"), code: http.StatusOK},
+				{handler: role("finance"), code: http.StatusUnauthorized},
+				{handler: permission("loan.read"), code: http.StatusOK},
+			}},
+			want: want{calls: 3},
+		},
+		{
+			name: "unauthorized is negatively cached",
+			args: args{
+				err: statusError{statusCode: http.StatusUnauthorized},
+				requests: []request{
+					{handler: permission("loan.read"), code: http.StatusUnauthorized},
+					{handler: role("admin"), code: http.StatusUnauthorized},
+				},
+			},
+			want: want{calls: 1},
+		},
+		{
+			name: "upstream error is not cached",
+			args: args{
+				err: statusError{statusCode: http.StatusBadGateway},
+				requests: []request{
+					{handler: permission("loan.read"), code: http.StatusUnauthorized},
+					{handler: permission("loan.read"), code: http.StatusUnauthorized},
+				},
+			},
+			want: want{calls: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rbacClient := &fakeRBAC{
+				me: &rbacModel.Me{
+					Email:       "jane@moladin.com",
+					Roles:       []string{"admin"},
+					Permissions: []string{"loan.*"},
+				},
+				err: tc.args.err,
+			}
+			middleware := auth.NewAuth(validator.New(),
+				auth.WithSentry(newSentryMock(t)),
+				auth.WithRBACClient(rbacClient),
+				auth.WithUserCache(auth.NewUserCache(validator.New(), auth.WithUserCacher(cache.NewInMemory()))),
+			)
+
+			for _, request := range tc.args.requests {
+				require.Equal(t, request.code, serveAuth(request.handler(middleware), token))
+			}
+			require.Equal(t, tc.want.calls, rbacClient.calls)
+		})
+	}
+}
+
+func TestNewUserCache_ErrorOnValidation(t *testing.T) {
+	require.Panics(t, func() {
+		auth.NewUserCache(validator.New())
+	})
+}
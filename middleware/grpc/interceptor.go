@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	"bitbucket.org/moladinTech/go-lib-common/registry"
+	commonValidator "bitbucket.org/moladinTech/go-lib-common/validator"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+)
+
+type Interceptor struct {
+	Registry registry.IRegistry `validate:"required"`
+}
+
+type Option func(*Interceptor)
+
+// WithRegistry set the registry having the sentry and the notification
+// client.
+func WithRegistry(registry registry.IRegistry) Option {
+	return func(i *Interceptor) {
+		i.Registry = registry
+	}
+}
+
+func NewInterceptor(
+	validator *validator.Validate,
+	options ...Option,
+) *Interceptor {
+	interceptor := &Interceptor{}
+
+	for _, option := range options {
+		option(interceptor)
+	}
+
+	err := validator.Struct(interceptor)
+	if err != nil {
+		panic(commonValidator.ToErrResponse(err))
+	}
+
+	return interceptor
+}
+
+// UnaryServerInterceptor log the error of the handler, capture it to
+// sentry and send the notification as decided by the notify policy of
+// errors.Report, like response.HttpErrResp does, then return it as grpc
+// status with ToStatus.
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, i.handleError(ctx, info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor of the streams.
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			return i.handleError(ss.Context(), info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+func (i *Interceptor) handleError(ctx context.Context, fullMethod string, err error) error {
+	commonError.Report(ctx, i.Registry, commonError.NotifyParam{
+		Err:        err,
+		StatusCode: httpStatusCode(err),
+		Route:      fullMethod,
+	})
+
+	return ToStatus(err).Err()
+}
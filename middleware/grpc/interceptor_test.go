@@ -0,0 +1,100 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	slackMock "bitbucket.org/moladinTech/go-lib-common/client/notification/slack/mocks"
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	commonGrpc "bitbucket.org/moladinTech/go-lib-common/middleware/grpc"
+	"bitbucket.org/moladinTech/go-lib-common/registry"
+	sentryMock "bitbucket.org/moladinTech/go-lib-common/sentry/mocks"
+	"bitbucket.org/moladinTech/go-lib-common/validator"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptor(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			code   codes.Code
+			notify bool
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "NoError",
+			args: args{err: nil},
+			want: want{code: codes.OK},
+		},
+		{
+			name: "ClientError",
+			args: args{err: commonError.New("TEST_GRPC_LOAN_CLOSED", http.StatusUnprocessableEntity, "Loan Is Closed")},
+			want: want{code: codes.InvalidArgument},
+		},
+		{
+			name: "ServerError",
+			args: args{err: commonError.WrapWithErr(errors.New("connection refused"), commonError.ErrSQLExec)},
+			want: want{code: codes.Internal, notify: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockedSentry := sentryMock.NewISentry(t)
+			mockedSlack := slackMock.NewISlack(t)
+			if testCase.want.notify {
+				mockedSentry.On("CaptureException", testCase.args.err).Return(&sentry.NewEvent().EventID).Twice()
+				mockedSlack.On("GetFormattedMessage", ctx, mock.Anything, testCase.args.err.Error()).Return("Hello").Twice()
+				mockedSlack.On("Send", ctx, "Hello").Return(nil).Twice()
+			}
+
+			interceptor := commonGrpc.NewInterceptor(validator.New(),
+				commonGrpc.WithRegistry(registry.NewRegistry(
+					registry.WithSentry(mockedSentry),
+					registry.WithNotif(mockedSlack),
+				)),
+			)
+
+			resp, err := interceptor.UnaryServerInterceptor()(ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/loan.v1.LoanService/GetLoan"},
+				func(ctx context.Context, req any) (any, error) {
+					return "resp", testCase.args.err
+				},
+			)
+			assert.Equal(t, "resp", resp)
+			assert.Equal(t, testCase.want.code, status.Code(err))
+
+			err = interceptor.StreamServerInterceptor()(nil, &serverStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/loan.v1.LoanService/WatchLoan"},
+				func(srv any, stream grpc.ServerStream) error {
+					return testCase.args.err
+				},
+			)
+			assert.Equal(t, testCase.want.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKeyData is the key of the json data of the response in the
+// metadata of errdetails.ErrorInfo.
+const MetadataKeyData = "data"
+
+// httpToCode and codeToHTTP are inverses of each other, except the aliases
+// 422 to InvalidArgument, AlreadyExists to 409 and OutOfRange to 400 that
+// come back as 400, Aborted and InvalidArgument.
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	499:                            codes.Canceled,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+var codeToHTTP = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+}
+
+// HTTPToCode return the grpc code of the http status code, the unknown
+// 4xx are InvalidArgument and the unknown 5xx are Internal.
+func HTTPToCode(statusCode int) codes.Code {
+	if code, ok := httpToCode[statusCode]; ok {
+		return code
+	}
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return codes.OK
+	case statusCode >= 400 && statusCode < 500:
+		return codes.InvalidArgument
+	case statusCode >= 500:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// CodeToHTTP return the http status code of the grpc code, 500 for the
+// others.
+func CodeToHTTP(code codes.Code) int {
+	if statusCode, ok := codeToHTTP[code]; ok {
+		return statusCode
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ToStatus convert err to the grpc status, using the response of err like
+// response.HttpErrResp does: the status code is mapped with HTTPToCode,
+// the message is the message of the response, the code and data are sent
+// as errdetails.ErrorInfo and the validation errors as errdetails.BadRequest.
+// The errors already having a grpc status are returned as is and the
+// errors without response are Internal.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+	if stderrors.As(err, &grpcStatus) {
+		return grpcStatus.GRPCStatus()
+	}
+
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+
+	responseMap, ok := commonError.GetResponse(err)
+	response, isResponse := responseMap.Response.(responseModel.Response)
+	if !ok || !isResponse {
+		return status.New(codes.Internal, http.StatusText(http.StatusInternalServerError))
+	}
+
+	st := status.New(HTTPToCode(responseMap.StatusCode), response.Message)
+
+	errorInfo := &errdetails.ErrorInfo{Reason: response.Code}
+	var badRequest *errdetails.BadRequest
+	switch data := response.Data.(type) {
+	case nil:
+	case []responseModel.ValidationResponse:
+		badRequest = &errdetails.BadRequest{}
+		for _, validation := range data {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       validation.Field,
+				Description: validation.Message,
+			})
+		}
+	default:
+		if raw, err := json.Marshal(data); err == nil {
+			errorInfo.Metadata = map[string]string{MetadataKeyData: string(raw)}
+		}
+	}
+
+	if errorInfo.Reason != "" || errorInfo.Metadata != nil {
+		if withDetails, err := st.WithDetails(errorInfo); err == nil {
+			st = withDetails
+		}
+	}
+	if badRequest != nil {
+		if withDetails, err := st.WithDetails(badRequest); err == nil {
+			st = withDetails
+		}
+	}
+
+	return st
+}
+
+// httpStatusCode return the http status code of err, used by the notify
+// policy.
+func httpStatusCode(err error) int {
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+	if stderrors.As(err, &grpcStatus) {
+		return CodeToHTTP(grpcStatus.GRPCStatus().Code())
+	}
+
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return CodeToHTTP(codes.Canceled)
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return CodeToHTTP(codes.DeadlineExceeded)
+	}
+
+	if responseMap, ok := commonError.GetResponse(err); ok {
+		return responseMap.StatusCode
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,155 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	commonGrpc "bitbucket.org/moladinTech/go-lib-common/middleware/grpc"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestToStatus(t *testing.T) {
+	type (
+		args struct {
+			err error
+		}
+		want struct {
+			code    codes.Code
+			message string
+			details []proto.Message
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	errLoanNotFound := commonError.New("TEST_GRPC_LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+
+	testCases := []testCase{
+		{
+			name: "Nil",
+			args: args{err: nil},
+			want: want{code: codes.OK},
+		},
+		{
+			name: "TypedError",
+			args: args{err: commonError.Wrap(errLoanNotFound.WithData(map[string]int{"loanId": 1}))},
+			want: want{
+				code:    codes.NotFound,
+				message: "Loan Not Found",
+				details: []proto.Message{&errdetails.ErrorInfo{
+					Reason:   "TEST_GRPC_LOAN_NOT_FOUND",
+					Metadata: map[string]string{commonGrpc.MetadataKeyData: `{"loanId":1}`},
+				}},
+			},
+		},
+		{
+			name: "MappedError",
+			args: args{err: commonError.WrapWithErr(errors.New("version mismatch"), commonError.ErrStaleObject)},
+			want: want{
+				code:    codes.Aborted,
+				message: "Data Has Been Modified By Another Request, Please Reload And Try Again",
+				details: []proto.Message{&errdetails.ErrorInfo{Reason: "STALE_OBJECT"}},
+			},
+		},
+		{
+			name: "ValidationError",
+			args: args{err: commonError.WithResponse(commonError.ErrValidation, http.StatusUnprocessableEntity, "Unprocessable Entity", []responseModel.ValidationResponse{
+				{Field: "amount", Message: "amount is a required field"},
+			})},
+			want: want{
+				code:    codes.InvalidArgument,
+				message: "Unprocessable Entity",
+				details: []proto.Message{&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: "amount", Description: "amount is a required field"},
+				}}},
+			},
+		},
+		{
+			name: "GrpcStatus",
+			args: args{err: status.Error(codes.Unavailable, "upstream unavailable")},
+			want: want{code: codes.Unavailable, message: "upstream unavailable"},
+		},
+		{
+			name: "ContextCanceled",
+			args: args{err: commonError.Wrap(context.Canceled)},
+			want: want{code: codes.Canceled, message: context.Canceled.Error()},
+		},
+		{
+			name: "UnknownError",
+			args: args{err: errors.New("connection refused")},
+			want: want{code: codes.Internal, message: "Internal Server Error"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			st := commonGrpc.ToStatus(testCase.args.err)
+
+			assert.Equal(t, testCase.want.code, st.Code())
+			assert.Equal(t, testCase.want.message, st.Message())
+			details := st.Details()
+			assert.Len(t, details, len(testCase.want.details))
+			for i := range testCase.want.details {
+				assert.True(t, proto.Equal(testCase.want.details[i], details[i].(proto.Message)), "%v", details[i])
+			}
+		})
+	}
+}
+
+func TestHTTPToCode(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, commonGrpc.HTTPToCode(http.StatusBadRequest))
+	assert.Equal(t, codes.InvalidArgument, commonGrpc.HTTPToCode(http.StatusTeapot))
+	assert.Equal(t, codes.Internal, commonGrpc.HTTPToCode(http.StatusBadGateway))
+	assert.Equal(t, http.StatusNotFound, commonGrpc.CodeToHTTP(codes.NotFound))
+	assert.Equal(t, http.StatusInternalServerError, commonGrpc.CodeToHTTP(codes.DataLoss))
+}
+
+func TestHTTPToCodeRoundTrip(t *testing.T) {
+	type (
+		args struct {
+			statusCode int
+		}
+		want struct {
+			code codes.Code
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{name: "OK", args: args{statusCode: http.StatusOK}, want: want{code: codes.OK}},
+		{name: "BadRequest", args: args{statusCode: http.StatusBadRequest}, want: want{code: codes.InvalidArgument}},
+		{name: "Unauthorized", args: args{statusCode: http.StatusUnauthorized}, want: want{code: codes.Unauthenticated}},
+		{name: "Forbidden", args: args{statusCode: http.StatusForbidden}, want: want{code: codes.PermissionDenied}},
+		{name: "NotFound", args: args{statusCode: http.StatusNotFound}, want: want{code: codes.NotFound}},
+		{name: "Conflict", args: args{statusCode: http.StatusConflict}, want: want{code: codes.Aborted}},
+		{name: "PreconditionFailed", args: args{statusCode: http.StatusPreconditionFailed}, want: want{code: codes.FailedPrecondition}},
+		{name: "TooManyRequests", args: args{statusCode: http.StatusTooManyRequests}, want: want{code: codes.ResourceExhausted}},
+		{name: "ClientClosedRequest", args: args{statusCode: 499}, want: want{code: codes.Canceled}},
+		{name: "InternalServerError", args: args{statusCode: http.StatusInternalServerError}, want: want{code: codes.Internal}},
+		{name: "NotImplemented", args: args{statusCode: http.StatusNotImplemented}, want: want{code: codes.Unimplemented}},
+		{name: "ServiceUnavailable", args: args{statusCode: http.StatusServiceUnavailable}, want: want{code: codes.Unavailable}},
+		{name: "GatewayTimeout", args: args{statusCode: http.StatusGatewayTimeout}, want: want{code: codes.DeadlineExceeded}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want.code, commonGrpc.HTTPToCode(testCase.args.statusCode))
+			assert.Equal(t, testCase.args.statusCode, commonGrpc.CodeToHTTP(testCase.want.code))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	"bitbucket.org/moladinTech/go-lib-common/i18n"
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	commonValidator "bitbucket.org/moladinTech/go-lib-common/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindAndValidate bind the uri params, the query and the json body of the
+// request into T then validate it with the shared validator.
+// On failure the response is written, 400 when the request can't be bound
+// and 422 with the errors of each field in data, and the error is returned
+// so the handler only has to return. The failure is logged as warning
+// without capturing it to sentry nor sending notification.
+//
+//	req, err := response.BindAndValidate[CreateLoanRequest](c)
+//	if err != nil {
+//	    return
+//	}
+func BindAndValidate[T any](c *gin.Context) (T, error) {
+	const logCtx = "common.response.BindAndValidate"
+
+	var (
+		ctx = c.Request.Context()
+		req T
+	)
+
+	if err := bind(c, &req); err != nil {
+		logger.Warn(ctx, err.Error(), logger.Tag{Key: "logCtx", Value: logCtx})
+		writeMappedError(c, commonError.ErrInvalidRequest, nil)
+		return req, commonError.ErrInvalidRequest
+	}
+
+	err := commonValidator.Shared().Struct(req)
+	if err == nil {
+		return req, nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		// T is not a struct
+		return req, nil
+	}
+
+	validationResponses := commonValidator.ToErrResponseLocale(validationErrors, i18n.FromRequest(c.Request))
+	for i, fieldError := range validationErrors {
+		validationResponses[i].Field = fieldPath(fieldError)
+	}
+
+	logger.Warn(ctx, commonError.ErrValidation.Error(),
+		logger.Tag{Key: "logCtx", Value: logCtx},
+		logger.Tag{Key: "errors", Value: validationResponses},
+	)
+	writeMappedError(c, commonError.ErrValidation, validationResponses)
+
+	return req, commonError.ErrValidation
+}
+
+func bind(c *gin.Context, req any) error {
+	if len(c.Params) > 0 {
+		if err := c.ShouldBindUri(req); err != nil {
+			return err
+		}
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		if err := c.ShouldBindWith(req, binding.Query); err != nil {
+			return err
+		}
+	}
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldPath return the namespace of the field without the name of the
+// request struct, e.g. address.city.
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+
+	return path
+}
+
+func writeMappedError(c *gin.Context, err error, data any) {
+	responseMap, _ := commonError.GetResponse(err)
+	response, _ := localize(c, responseMap).(responseModel.Response)
+	response.Data = data
+
+	writeError(c, responseMap.StatusCode, response)
+}
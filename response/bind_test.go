@@ -0,0 +1,115 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commonResponse "bitbucket.org/moladinTech/go-lib-common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	address struct {
+		City string `json:"city" validate:"required"`
+	}
+	createLoanRequest struct {
+		UserId  int64   `uri:"userId" validate:"required"`
+		Source  string  `form:"source" validate:"required"`
+		Amount  int64   `json:"amount" validate:"required,min=1000"`
+		Address address `json:"address"`
+	}
+)
+
+func Test_BindAndValidate(t *testing.T) {
+	type (
+		args struct {
+			path           string
+			body           string
+			acceptLanguage string
+		}
+		want struct {
+			statusCode int
+			body       string
+			req        createLoanRequest
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	testCases := []testCase{
+		{
+			name: "Valid",
+			args: args{path: "/users/1/loans?source=web", body: `{"amount":5000,"address":{"city":"Jakarta"}}`},
+			want: want{
+				statusCode: http.StatusOK,
+				req:        createLoanRequest{UserId: 1, Source: "web", Amount: 5000, Address: address{City: "Jakarta"}},
+			},
+		},
+		{
+			name: "InvalidFields",
+			args: args{path: "/users/1/loans", body: `{"amount":10}`},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				body: `{"status":"fail","message":"Unprocessable Entity","code":"VALIDATION","data":[
+					{"field":"source","message":"source is a required field"},
+					{"field":"amount","message":"amount must be a minimum of 1000 in length"},
+					{"field":"address.city","message":"city is a required field"}
+				]}`,
+			},
+		},
+		{
+			name: "InvalidFieldsIndonesian",
+			args: args{path: "/users/1/loans?source=web", body: `{"amount":5000}`, acceptLanguage: "id-ID,id;q=0.9"},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				body: `{"status":"fail","message":"Data Tidak Valid","code":"VALIDATION","data":[
+					{"field":"address.city","message":"city wajib diisi"}
+				]}`,
+			},
+		},
+		{
+			name: "MalformedJSON",
+			args: args{path: "/users/1/loans?source=web", body: `{"amount":`},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				body:       `{"status":"fail","message":"Bad Request","code":"INVALID_REQUEST"}`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var req createLoanRequest
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/users/:userId/loans", func(c *gin.Context) {
+				var err error
+				req, err = commonResponse.BindAndValidate[createLoanRequest](c)
+				if err != nil {
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			httpReq, _ := http.NewRequest(http.MethodPost, testCase.args.path, strings.NewReader(testCase.args.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			httpReq.Header.Set("Accept-Language", testCase.args.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, testCase.want.statusCode, w.Code)
+			if testCase.want.body != "" {
+				assert.JSONEq(t, testCase.want.body, w.Body.String())
+				return
+			}
+			assert.Equal(t, testCase.want.req, req)
+		})
+	}
+}
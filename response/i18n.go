@@ -0,0 +1,33 @@
+package response
+
+import (
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	"bitbucket.org/moladinTech/go-lib-common/i18n"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localize return the response of responseMap with the message of the
+// locale matching the Accept-Language header, the message is kept when
+// the locale has no message for the key.
+func localize(c *gin.Context, responseMap commonError.Response) any {
+	response, ok := responseMap.Response.(responseModel.Response)
+	if !ok {
+		return responseMap.Response
+	}
+
+	key := responseMap.MessageKey
+	if key == "" {
+		key = response.Code
+	}
+	if key == "" {
+		return response
+	}
+
+	if message, ok := i18n.Lookup(i18n.FromRequest(c.Request), key); ok {
+		response.Message = message
+	}
+
+	return response
+}
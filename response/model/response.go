@@ -1,5 +1,9 @@
 package model
 
+import (
+	"encoding/json"
+)
+
 const (
 	StatusSuccess = "success"
 	StatusFail    = "fail"
@@ -19,9 +23,64 @@ type Response struct {
 	NextPage     uint   `json:"nextPage,omitempty"`
 	PreviousPage uint   `json:"previousPage,omitempty"`
 	TotalPages   uint   `json:"totalPages,omitempty"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+}
+
+// PageInfo is the pagination part of Response, filled by the pagination
+// helpers in data_source.
+type PageInfo struct {
+	Limit        uint
+	TotalRecords uint64
+	CurrentPage  uint
+	NextPage     uint
+	PreviousPage uint
+	TotalPages   uint
+	NextCursor   string
+}
+
+// SetPageInfo copy the pagination fields of info into the response.
+func (r *Response) SetPageInfo(info PageInfo) {
+	r.Limit = info.Limit
+	r.TotalRecords = info.TotalRecords
+	r.CurrentPage = info.CurrentPage
+	r.NextPage = info.NextPage
+	r.PreviousPage = info.PreviousPage
+	r.TotalPages = info.TotalPages
+	r.NextCursor = info.NextCursor
 }
 
 type ValidationResponse struct {
 	Field   string `json:"field,omitempty"`
 	Message string `json:"message,omitempty"`
 }
+
+// Problem is the RFC 7807 problem details, the extension members are
+// marshaled next to the standard members.
+// ref: https://www.rfc-editor.org/rfc/rfc7807
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
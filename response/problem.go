@@ -0,0 +1,103 @@
+package response
+
+import (
+	"net/http"
+
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	// ProblemTypeDefault is the type of the problems without code or
+	// without type base URI.
+	ProblemTypeDefault = "about:blank"
+
+	problemConfigKey = "common.response.problem"
+)
+
+type problemConfig struct {
+	typeBaseURI string
+}
+
+type ProblemOption func(*problemConfig)
+
+// WithProblemTypeBaseURI set the base URI of the problem type, the type is
+// the base URI followed by the error code, e.g.
+// https://developer.moladin.com/problems/LOAN_NOT_FOUND.
+func WithProblemTypeBaseURI(typeBaseURI string) ProblemOption {
+	return func(c *problemConfig) {
+		c.typeBaseURI = typeBaseURI
+	}
+}
+
+// ProblemJSON render the errors of HttpErrResp, HttpResp, BindAndValidate
+// and RouteNotFound as application/problem+json (RFC 7807) for the engine
+// or the route group using it.
+func ProblemJSON(opts ...ProblemOption) gin.HandlerFunc {
+	config := &problemConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(c *gin.Context) {
+		c.Set(problemConfigKey, config)
+		c.Next()
+	}
+}
+
+// writeError write the error response as problem+json when ProblemJSON is
+// used, as it is otherwise.
+func writeError(c *gin.Context, statusCode int, response any) {
+	value, ok := c.Get(problemConfigKey)
+	config, isProblem := value.(*problemConfig)
+	resp, isResponse := response.(responseModel.Response)
+	setRequestIDHeader(c)
+	if !ok || !isProblem || !isResponse {
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(statusCode, newProblem(c, config, statusCode, resp))
+}
+
+func newProblem(c *gin.Context, config *problemConfig, statusCode int, resp responseModel.Response) responseModel.Problem {
+	problem := responseModel.Problem{
+		Type:       ProblemTypeDefault,
+		Title:      http.StatusText(statusCode),
+		Status:     statusCode,
+		Detail:     resp.Message,
+		Instance:   requestID(c),
+		Extensions: make(map[string]any),
+	}
+
+	if resp.Code != "" {
+		problem.Extensions["code"] = resp.Code
+		if config.typeBaseURI != "" {
+			problem.Type = config.typeBaseURI + resp.Code
+		}
+	}
+
+	switch data := resp.Data.(type) {
+	case nil:
+	case []responseModel.ValidationResponse:
+		problem.Extensions["errors"] = data
+	default:
+		problem.Extensions["data"] = data
+	}
+
+	return problem
+}
+
+func requestID(c *gin.Context) string {
+	if c.Request != nil {
+		if requestID, ok := c.Request.Context().Value(constant.XRequestIdHeader).(string); ok && requestID != "" {
+			return requestID
+		}
+	}
+
+	return c.Writer.Header().Get(constant.XRequestIdHeader)
+}
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
-	"bitbucket.org/moladinTech/go-lib-common/logger"
 	"bitbucket.org/moladinTech/go-lib-common/registry"
 	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
 	"github.com/gin-gonic/gin"
@@ -15,7 +14,7 @@ import (
 // It will imediately return error 404 not found.
 func RouteNotFound(e *gin.Engine) {
 	e.NoRoute(func(ctx *gin.Context) {
-		ctx.JSON(http.StatusNotFound, responseModel.Response{
+		writeError(ctx, http.StatusNotFound, responseModel.Response{
 			Message: http.StatusText(http.StatusNotFound),
 			Status:  responseModel.StatusFail,
 		})
@@ -29,46 +28,25 @@ type ParamHttpErrResp struct {
 	Data     interface{}
 }
 
-// HttpErrResp is helper to logger the error, send response and send notification (if statusCode >= 500)
+// HttpErrResp is helper to logger the error, send response and send notification as decided by the
+// notify policy of errors.Report (default if statusCode >= 500)
 func HttpErrResp(ctx context.Context, p ParamHttpErrResp) {
-	// SetErrCustomResponse to add error in MapErrorResponse
-	commonError.SetErrCustomResponse()
 	var (
 		c   = p.GinCtx
 		e   = p.Err
 		rgs = p.Registry
 
-		responseMap, isMapMatch    = commonError.MapErrorResponse[commonError.GetErrKey(e)]
-		matchedError, isErrorMatch = commonError.ErrorMatcher(e)
-		logCtx                     string
+		responseMap, isMapMatch = commonError.GetResponse(e)
 	)
 
-	if isErrorMatch {
-		logCtx = matchedError.GetLogCtx()
-		logger.Error(ctx, `error`, e, logger.Tag{Key: "logCtx", Value: logCtx})
-	} else {
-		logger.Error(ctx, `error`, e)
-	}
-
-	SendNotifParam := commonError.ParamIsSendNotif{
-		IsMapMatch:   isMapMatch,
-		ResponseMap:  responseMap,
-		IsErrorMatch: isErrorMatch,
-		MatchedError: matchedError,
-	}
-
-	if commonError.IsCaptureErrorAndSendNotif(SendNotifParam) {
-		rgs.GetSentry().CaptureException(e)
-		// send notif
-		slackMessage := rgs.GetNotif().GetFormattedMessage(ctx, logCtx, e)
-		errSlack := rgs.GetNotif().Send(ctx, slackMessage)
-		if errSlack != nil {
-			logger.Error(ctx, "Error sending notif to slack", errSlack)
-		}
-	}
+	commonError.Report(ctx, rgs, commonError.NotifyParam{
+		Err:        e,
+		StatusCode: responseMap.StatusCode,
+		Route:      c.FullPath(),
+	})
 
 	if !isMapMatch {
-		c.JSON(http.StatusInternalServerError, responseModel.Response{
+		writeError(c, http.StatusInternalServerError, responseModel.Response{
 			Message: http.StatusText(http.StatusInternalServerError),
 			Status:  responseModel.StatusFail,
 		})
@@ -83,7 +61,7 @@ func HttpErrResp(ctx context.Context, p ParamHttpErrResp) {
 		responseMap.Response = val
 	}
 
-	c.JSON(responseMap.StatusCode, responseMap.Response)
+	writeError(c, responseMap.StatusCode, localize(c, responseMap))
 
 }
 
@@ -97,60 +75,40 @@ func (h *httpResp) Return(statusCode int, response interface{}) {
 	}
 }
 
-// HttpResp is helper to logger the error, send response and send notification (if statusCode >= 500)
+// HttpResp is helper to logger the error, send response and send notification as decided by the
+// notify policy of errors.Report (default if statusCode >= 500)
 func HttpResp(ctx context.Context, e error, p ParamHttpErrResp) *httpResp {
-	// SetErrCustomResponse to add error in MapErrorResponse
-	commonError.SetErrCustomResponse()
 	var (
 		c   = p.GinCtx
 		rgs = p.Registry
 		hr  = &httpResp{GinCtx: c}
 
-		responseMap, isMapMatch    = commonError.MapErrorResponse[commonError.GetErrKey(e)]
+		responseMap, isMapMatch    = commonError.GetResponse(e)
 		matchedError, isErrorMatch = commonError.ErrorMatcher(e)
-		logCtx                     string
 	)
 
 	if e == nil && !isErrorMatch {
 		return hr
 	}
 
-	if isErrorMatch {
-		logCtx = matchedError.GetLogCtx()
-		logger.Error(ctx, `error`, e, logger.Tag{Key: "logCtx", Value: logCtx})
-	} else {
-		logger.Error(ctx, `error`, e)
-	}
-
-	SendNotifParam := commonError.ParamIsSendNotif{
-		IsMapMatch:   isMapMatch,
-		ResponseMap:  responseMap,
-		IsErrorMatch: isErrorMatch,
-		MatchedError: matchedError,
-	}
-
-	if commonError.IsCaptureErrorAndSendNotif(SendNotifParam) {
-		rgs.GetSentry().CaptureException(e)
-		// send notif
-		slackMessage := rgs.GetNotif().GetFormattedMessage(ctx, logCtx, e)
-		errSlack := rgs.GetNotif().Send(ctx, slackMessage)
-		if errSlack != nil {
-			logger.Error(ctx, "Error sending notif to slack", errSlack)
-		}
-	}
+	commonError.Report(ctx, rgs, commonError.NotifyParam{
+		Err:        e,
+		StatusCode: responseMap.StatusCode,
+		Route:      c.FullPath(),
+	})
 
 	if isErrorMatch && matchedError.GetIsSuccessResp() {
 		return hr
 	}
 
 	if !isMapMatch {
-		c.JSON(http.StatusInternalServerError, responseModel.Response{
+		writeError(c, http.StatusInternalServerError, responseModel.Response{
 			Message: http.StatusText(http.StatusInternalServerError),
 			Status:  responseModel.StatusFail,
 		})
 		return nil
 	}
 
-	c.JSON(responseMap.StatusCode, responseMap.Response)
+	writeError(c, responseMap.StatusCode, localize(c, responseMap))
 	return nil
 }
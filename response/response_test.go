@@ -8,8 +8,12 @@ import (
 	"testing"
 
 	slackMock "bitbucket.org/moladinTech/go-lib-common/client/notification/slack/mocks"
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	commonError "bitbucket.org/moladinTech/go-lib-common/errors"
+	"bitbucket.org/moladinTech/go-lib-common/i18n"
 	"bitbucket.org/moladinTech/go-lib-common/registry"
 	commonResponse "bitbucket.org/moladinTech/go-lib-common/response"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
 	sentryMock "bitbucket.org/moladinTech/go-lib-common/sentry/mocks"
 	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
@@ -40,7 +44,7 @@ func Test_HttpRespWithError(t *testing.T) {
 	mockedSentry.On("CaptureException", dummyError).
 		Return(&sentry.NewEvent().EventID)
 	mockedSlack.On("GetFormattedMessage",
-		context.Background(), mock.Anything, dummyError,
+		context.Background(), mock.Anything, dummyError.Error(),
 	).Return("Hello")
 	mockedSlack.On("Send",
 		context.Background(), "Hello",
@@ -77,7 +81,7 @@ func Test_HttpErrRespWithError(t *testing.T) {
 	mockedSentry.On("CaptureException", dummyError).
 		Return(&sentry.NewEvent().EventID)
 	mockedSlack.On("GetFormattedMessage",
-		context.Background(), mock.Anything, dummyError,
+		context.Background(), mock.Anything, dummyError.Error(),
 	).Return("Hello")
 	mockedSlack.On("Send",
 		context.Background(), "Hello",
@@ -98,3 +102,137 @@ func Test_HttpErrRespWithError(t *testing.T) {
 
 	assert.Equal(t, ginCtx.Writer.Status(), http.StatusInternalServerError)
 }
+
+func Test_HttpErrRespWithTypedError(t *testing.T) {
+	mockedSentry := sentryMock.NewISentry(t)
+	mockedSlack := slackMock.NewISlack(t)
+	registryClient := registry.NewRegistry(
+		registry.WithSlack(mockedSlack),
+		registry.WithSentry(mockedSentry),
+		registry.WithNotif(mockedSlack),
+	)
+
+	errLoanNotFound := commonError.New("LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+
+	httpRecorder := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(httpRecorder)
+	commonResponse.HttpErrResp(context.Background(), commonResponse.ParamHttpErrResp{
+		Err:      errLoanNotFound.WithData(map[string]int{"loanId": 1}),
+		GinCtx:   ginCtx,
+		Registry: registryClient,
+	})
+
+	assert.Equal(t, http.StatusNotFound, httpRecorder.Code)
+	assert.JSONEq(t, `{"status":"fail","message":"Loan Not Found","code":"LOAN_NOT_FOUND","data":{"loanId":1}}`, httpRecorder.Body.String())
+}
+
+func Test_HttpErrRespProblemJSON(t *testing.T) {
+	type (
+		args struct {
+			path string
+			err  error
+		}
+		want struct {
+			statusCode  int
+			contentType string
+			body        string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	errLoanNotFound := commonError.New("LOAN_NOT_FOUND", http.StatusNotFound, "Loan Not Found")
+
+	testCases := []testCase{
+		{
+			name: "ProblemGroup",
+			args: args{path: "/v2/loans", err: errLoanNotFound.WithData(map[string]int{"loanId": 1})},
+			want: want{
+				statusCode:  http.StatusNotFound,
+				contentType: commonResponse.ProblemContentType,
+				body:        `{"type":"https://problems.moladin.com/LOAN_NOT_FOUND","title":"Not Found","status":404,"detail":"Loan Not Found","instance":"request-1","code":"LOAN_NOT_FOUND","data":{"loanId":1}}`,
+			},
+		},
+		{
+			name: "ProblemGroupValidation",
+			args: args{path: "/v2/loans", err: commonError.WithResponse(nil, http.StatusUnprocessableEntity, "Unprocessable Entity", []responseModel.ValidationResponse{{Field: "amount", Message: "amount is a required field"}})},
+			want: want{
+				statusCode:  http.StatusUnprocessableEntity,
+				contentType: commonResponse.ProblemContentType,
+				body:        `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"Unprocessable Entity","instance":"request-1","errors":[{"field":"amount","message":"amount is a required field"}]}`,
+			},
+		},
+		{
+			name: "DefaultGroup",
+			args: args{path: "/v1/loans", err: errLoanNotFound},
+			want: want{
+				statusCode:  http.StatusNotFound,
+				contentType: "application/json; charset=utf-8",
+				body:        `{"status":"fail","message":"Loan Not Found","code":"LOAN_NOT_FOUND"}`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			registryClient := registry.NewRegistry(
+				registry.WithSlack(slackMock.NewISlack(t)),
+				registry.WithSentry(sentryMock.NewISentry(t)),
+				registry.WithNotif(slackMock.NewISlack(t)),
+			)
+			handler := func(c *gin.Context) {
+				commonResponse.HttpErrResp(c.Request.Context(), commonResponse.ParamHttpErrResp{
+					Err:      testCase.args.err,
+					GinCtx:   c,
+					Registry: registryClient,
+				})
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				ctx := context.WithValue(c.Request.Context(), constant.XRequestIdHeader, "request-1")
+				c.Request = c.Request.WithContext(ctx)
+				c.Next()
+			})
+			r.GET("/v1/loans", handler)
+			r.Group("/v2", commonResponse.ProblemJSON(
+				commonResponse.WithProblemTypeBaseURI("https://problems.moladin.com/"),
+			)).GET("/loans", handler)
+
+			req, _ := http.NewRequest(http.MethodGet, testCase.args.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.want.statusCode, w.Code)
+			assert.Equal(t, testCase.want.contentType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, testCase.want.body, w.Body.String())
+		})
+	}
+}
+
+func Test_HttpErrRespLocalized(t *testing.T) {
+	errLoanClosed := commonError.New("TEST_LOAN_CLOSED", http.StatusUnprocessableEntity, "Loan Is Closed",
+		commonError.WithMessageKey("loan.closed"),
+	)
+	i18n.Register(i18n.LocaleID, map[string]string{"loan.closed": "Pinjaman Sudah Ditutup"})
+
+	for acceptLanguage, message := range map[string]string{"": "Loan Is Closed", "en-US": "Loan Is Closed", "id": "Pinjaman Sudah Ditutup"} {
+		httpRecorder := httptest.NewRecorder()
+		ginCtx, _ := gin.CreateTestContext(httpRecorder)
+		ginCtx.Request, _ = http.NewRequest(http.MethodPost, "/loans", nil)
+		ginCtx.Request.Header.Set(i18n.HeaderAcceptLanguage, acceptLanguage)
+
+		commonResponse.HttpErrResp(context.Background(), commonResponse.ParamHttpErrResp{
+			Err:      errLoanClosed,
+			GinCtx:   ginCtx,
+			Registry: registry.NewRegistry(),
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, httpRecorder.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"`+message+`","code":"TEST_LOAN_CLOSED"}`, httpRecorder.Body.String())
+	}
+}
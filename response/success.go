@@ -0,0 +1,109 @@
+package response
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	"bitbucket.org/moladinTech/go-lib-common/exporter"
+	"bitbucket.org/moladinTech/go-lib-common/logger"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const MIMECSV = "text/csv"
+
+type paginatedConfig struct {
+	csv             bool
+	csvFileName     string
+	exporterOptions []exporter.Option
+}
+
+type PaginatedOption func(*paginatedConfig)
+
+// WithCSVNegotiation return the items as csv attachment named fileName
+// when the client prefers text/csv in the Accept header, the exporter
+// options (AddConverter) are used to format the columns.
+func WithCSVNegotiation(fileName string, opts ...exporter.Option) PaginatedOption {
+	return func(c *paginatedConfig) {
+		c.csv = true
+		c.csvFileName = fileName
+		c.exporterOptions = opts
+	}
+}
+
+// OK write 200 with data.
+func OK(c *gin.Context, data any) {
+	writeSuccess(c, http.StatusOK, responseModel.Response{Data: data})
+}
+
+// Created write 201 with the created data.
+func Created(c *gin.Context, data any) {
+	writeSuccess(c, http.StatusCreated, responseModel.Response{Data: data})
+}
+
+// Accepted write 202 with data, e.g. the id of the queued job.
+func Accepted(c *gin.Context, data any) {
+	writeSuccess(c, http.StatusAccepted, responseModel.Response{Data: data})
+}
+
+// NoContent write 204 without body.
+func NoContent(c *gin.Context) {
+	setRequestIDHeader(c)
+	c.Status(http.StatusNoContent)
+}
+
+// Paginated write 200 with items in data and the pagination fields of
+// pageInfo, or the items as csv with WithCSVNegotiation.
+func Paginated(c *gin.Context, items any, pageInfo responseModel.PageInfo, opts ...PaginatedOption) {
+	const logCtx = "common.response.Paginated"
+
+	config := &paginatedConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.csv && c.NegotiateFormat(gin.MIMEJSON, MIMECSV) == MIMECSV {
+		var buffer bytes.Buffer
+		writer := exporter.NewCSVStreamWriter(&buffer, config.exporterOptions...)
+		if err := writer.Write(items); err != nil {
+			logger.Error(c.Request.Context(), `error`, err, logger.Tag{Key: "logCtx", Value: logCtx})
+			writeError(c, http.StatusInternalServerError, responseModel.Response{
+				Message: http.StatusText(http.StatusInternalServerError),
+				Status:  responseModel.StatusFail,
+			})
+			return
+		}
+
+		setRequestIDHeader(c)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, config.csvFileName))
+		c.Data(http.StatusOK, MIMECSV, buffer.Bytes())
+		return
+	}
+
+	response := responseModel.Response{Data: items}
+	response.SetPageInfo(pageInfo)
+	writeSuccess(c, http.StatusOK, response)
+}
+
+func writeSuccess(c *gin.Context, statusCode int, response responseModel.Response) {
+	response.Status = responseModel.StatusSuccess
+	response.Message = http.StatusText(statusCode)
+
+	setRequestIDHeader(c)
+	c.JSON(statusCode, response)
+}
+
+// setRequestIDHeader add X-Request-Id when it isn't set by the RequestID
+// middleware yet.
+func setRequestIDHeader(c *gin.Context) {
+	if c.Writer.Header().Get(constant.XRequestIdHeader) != "" {
+		return
+	}
+
+	if requestID := requestID(c); requestID != "" {
+		c.Header(constant.XRequestIdHeader, requestID)
+	}
+}
@@ -0,0 +1,149 @@
+package response_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/moladinTech/go-lib-common/constant"
+	"bitbucket.org/moladinTech/go-lib-common/exporter"
+	commonResponse "bitbucket.org/moladinTech/go-lib-common/response"
+	responseModel "bitbucket.org/moladinTech/go-lib-common/response/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type loan struct {
+	Id     int64  `json:"id" exporter:"ID"`
+	Status string `json:"status" exporter:"Status"`
+}
+
+func Test_SuccessResponse(t *testing.T) {
+	type (
+		args struct {
+			handler gin.HandlerFunc
+			accept  string
+		}
+		want struct {
+			statusCode  int
+			contentType string
+			body        string
+		}
+		testCase struct {
+			name string
+			args
+			want
+		}
+	)
+
+	loans := []loan{{Id: 1, Status: "active"}, {Id: 2, Status: "closed"}}
+	pageInfo := responseModel.PageInfo{Limit: 2, TotalRecords: 4, CurrentPage: 1, NextPage: 2, TotalPages: 2}
+	paginated := func(c *gin.Context) {
+		commonResponse.Paginated(c, loans, pageInfo,
+			commonResponse.WithCSVNegotiation("loans.csv", exporter.AddConverter(loan{}, map[string]exporter.FuncConvert{
+				"Status": func(v interface{}) string { return v.(string) + "!" },
+			})),
+		)
+	}
+
+	testCases := []testCase{
+		{
+			name: "OK",
+			args: args{handler: func(c *gin.Context) { commonResponse.OK(c, loans[0]) }},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: gin.MIMEJSON,
+				body:        `{"status":"success","message":"OK","data":{"id":1,"status":"active"}}`,
+			},
+		},
+		{
+			name: "Created",
+			args: args{handler: func(c *gin.Context) { commonResponse.Created(c, loans[0]) }},
+			want: want{
+				statusCode:  http.StatusCreated,
+				contentType: gin.MIMEJSON,
+				body:        `{"status":"success","message":"Created","data":{"id":1,"status":"active"}}`,
+			},
+		},
+		{
+			name: "Accepted",
+			args: args{handler: func(c *gin.Context) { commonResponse.Accepted(c, map[string]string{"jobId": "job-1"}) }},
+			want: want{
+				statusCode:  http.StatusAccepted,
+				contentType: gin.MIMEJSON,
+				body:        `{"status":"success","message":"Accepted","data":{"jobId":"job-1"}}`,
+			},
+		},
+		{
+			name: "NoContent",
+			args: args{handler: commonResponse.NoContent},
+			want: want{statusCode: http.StatusNoContent},
+		},
+		{
+			name: "PaginatedJSON",
+			args: args{handler: paginated, accept: "application/json"},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: gin.MIMEJSON,
+				body: `{"status":"success","message":"OK","data":[{"id":1,"status":"active"},{"id":2,"status":"closed"}],
+					"limit":2,"totalRecords":4,"currentPage":1,"nextPage":2,"totalPages":2}`,
+			},
+		},
+		{
+			name: "PaginatedCSV",
+			args: args{handler: paginated, accept: "text/csv"},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: commonResponse.MIMECSV,
+				body:        "ID,Status\n1,active!\n2,closed!\n",
+			},
+		},
+		{
+			name: "PaginatedCSVWithoutItems",
+			args: args{handler: func(c *gin.Context) {
+				commonResponse.Paginated(c, []loan(nil), pageInfo, commonResponse.WithCSVNegotiation("loans.csv"))
+			}, accept: "text/csv"},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: commonResponse.MIMECSV,
+				body:        "ID,Status\n",
+			},
+		},
+		{
+			name: "PaginatedCSVNilItems",
+			args: args{handler: func(c *gin.Context) {
+				commonResponse.Paginated(c, nil, pageInfo, commonResponse.WithCSVNegotiation("loans.csv"))
+			}, accept: "text/csv"},
+			want: want{
+				statusCode:  http.StatusInternalServerError,
+				contentType: gin.MIMEJSON,
+				body:        `{"status":"fail","message":"Internal Server Error"}`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/loans", testCase.args.handler)
+
+			req, _ := http.NewRequest(http.MethodGet, "/loans", nil)
+			req.Header.Set("Accept", testCase.args.accept)
+			req = req.WithContext(context.WithValue(req.Context(), constant.XRequestIdHeader, "request-1"))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.want.statusCode, w.Code)
+			assert.Equal(t, "request-1", w.Header().Get(constant.XRequestIdHeader))
+			if testCase.want.contentType == gin.MIMEJSON {
+				assert.Contains(t, w.Header().Get("Content-Type"), gin.MIMEJSON)
+				assert.JSONEq(t, testCase.want.body, w.Body.String())
+				return
+			}
+			assert.Equal(t, testCase.want.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.want.body, w.Body.String())
+		})
+	}
+}
@@ -2,8 +2,11 @@ package validator
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 
+	"bitbucket.org/moladinTech/go-lib-common/i18n"
 	modelResp "bitbucket.org/moladinTech/go-lib-common/response/model"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -13,8 +16,26 @@ import (
 
 var ErrValidator = map[string]string{}
 
-func New() *validator.Validate {
+var (
+	shared     *validator.Validate
+	sharedOnce sync.Once
+)
+
+type Option func(*validator.Validate)
+
+// WithJSONFieldName report the json name of the fields in the validation
+// errors, falling back to the form and uri name, instead of the Go name.
+func WithJSONFieldName() Option {
+	return func(v *validator.Validate) {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+func New(opts ...Option) *validator.Validate {
 	validate := validator.New()
+	for _, opt := range opts {
+		opt(validate)
+	}
 	en := en.New()
 	uni := ut.New(en, en)
 	trans, _ := uni.GetTranslator("en")
@@ -24,164 +45,94 @@ func New() *validator.Validate {
 	return validate
 }
 
+// Shared return the validator shared by response.BindAndValidate, the
+// custom validations must be registered to it before serving requests.
+func Shared() *validator.Validate {
+	sharedOnce.Do(func() {
+		shared = New(WithJSONFieldName())
+	})
+
+	return shared
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
 func ToErrResponse(err error) string {
 	var errors []string
 	if fieldErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, err := range fieldErrors {
-			switch err.Tag() {
-			case "required":
-				errors = append(errors, fmt.Sprintf("%s is a required field", err.Field()))
-			case "len":
-				errors = append(errors, fmt.Sprintf("%s must be a %s length", err.Field(), err.Param()))
-			case "min":
-				errors = append(errors, fmt.Sprintf("%s must be a minimum of %s in length", err.Field(), err.Param()))
-			case "max":
-				errors = append(errors, fmt.Sprintf("%s must be a maximum of %s in length", err.Field(), err.Param()))
-			case "url":
-				errors = append(errors, fmt.Sprintf("%s must be a valid URL", err.Field()))
-			case "oneof":
-				errors = append(errors, fmt.Sprintf("%s must be an oneof [%s]", err.Field(), err.Param()))
-			case "required_if":
-				params := strings.Split(err.Param(), " ")
-				formattedParams := params[0]
-				for i, param := range params {
-					if i > 0 {
-						if i%2 != 0 {
-							formattedParams += fmt.Sprintf(" is %s", param)
-						} else {
-							formattedParams += fmt.Sprintf(" and %s", param)
-						}
-					}
-				}
-				errors = append(errors, fmt.Sprintf("%s is a required if %s", err.Field(), formattedParams))
-			case "required_unless":
-				paramString := err.Param()
-				formattedParams := strings.Replace(paramString, " ", " is not ", -1)
-				errors = append(errors, fmt.Sprintf("%s is a required if %s", err.Field(), formattedParams))
-			case "required_without":
-				errors = append(errors, fmt.Sprintf("%s is a required if %s is empty", err.Field(), err.Param()))
-			case "required_without_all":
-				errors = append(errors, fmt.Sprintf("%s is a required if %s are empty", err.Field(), err.Param()))
-			case "required_with":
-				errors = append(errors, fmt.Sprintf("%s is a required if %s is not empty", err.Field(), err.Param()))
-			case "excluded_with":
-				errors = append(errors, fmt.Sprintf("%s is a exclude if %s is empty", err.Field(), err.Param()))
-			case "ltecsfield":
-				errors = append(errors, fmt.Sprintf("%s is less than to another %s field", err.Field(), err.Param()))
-			default:
-				errors = append(errors, fmt.Sprintf("something wrong on %s; %s", err.Field(), err.Tag()))
-			}
+			errors = append(errors, fieldMessage(err, i18n.DefaultLocale, false))
 		}
 	}
 	return strings.Join(errors, ",")
 }
 
 func ToErrResponseV2(err error) (validationResponses []modelResp.ValidationResponse) {
+	return ToErrResponseLocale(err, i18n.DefaultLocale)
+}
 
+// ToErrResponseLocale is ToErrResponseV2 with the messages of locale, the
+// messages of the custom tags are registered with i18n.Register as
+// validation.<tag>, ErrValidator is used otherwise.
+func ToErrResponseLocale(err error, locale string) (validationResponses []modelResp.ValidationResponse) {
 	if fieldErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, err := range fieldErrors {
-			switch err.Tag() {
-			case "required":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required field", err.Field()),
-				})
-			case "len":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s must be a %s length", err.Field(), err.Param()),
-				})
-			case "min":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s must be a minimum of %s in length", err.Field(), err.Param()),
-				})
-			case "max":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s must be a maximum of %s in length", err.Field(), err.Param()),
-				})
-			case "url":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s must be a valid URL", err.Field()),
-				})
-			case "oneof":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s must be an oneof [%s]", err.Field(), err.Param()),
-				})
-			case "required_if":
-				params := strings.Split(err.Param(), " ")
-				formattedParams := params[0]
-				for i, param := range params {
-					if i > 0 {
-						if i%2 != 0 {
-							formattedParams += fmt.Sprintf(" is %s", param)
-						} else {
-							formattedParams += fmt.Sprintf(" and %s", param)
-						}
-					}
-				}
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required if %s", err.Field(), formattedParams),
-				})
-			case "required_unless":
-				paramString := err.Param()
-				formattedParams := strings.Replace(paramString, " ", " is not ", -1)
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required if %s", err.Field(), formattedParams),
-				})
-			case "required_without":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required if %s is empty", err.Field(), err.Param()),
-				})
-			case "required_without_all":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required if %s are empty", err.Field(), err.Param()),
-				})
-			case "required_with":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a required if %s is not empty", err.Field(), err.Param()),
-				})
-			case "excluded_with":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is a exclude if %s is empty", err.Field(), err.Param()),
-				})
-			case "ltecsfield":
-				validationResponses = append(validationResponses, modelResp.ValidationResponse{
-					Field:   err.Field(),
-					Message: fmt.Sprintf("%s is less than to another %s field", err.Field(), err.Param()),
-				})
-			default:
-				errValidator, ok := ErrValidator[err.Tag()]
-				if ok {
-					count := strings.Count(errValidator, "%s")
-					if count == 1 {
-						validationResponses = append(validationResponses, modelResp.ValidationResponse{
-							Field:   err.Field(),
-							Message: fmt.Sprintf(errValidator, err.Field()),
-						})
-					} else {
-						validationResponses = append(validationResponses, modelResp.ValidationResponse{
-							Field:   err.Field(),
-							Message: fmt.Sprintf(errValidator, err.Field(), err.Param()),
-						})
-					}
+			validationResponses = append(validationResponses, modelResp.ValidationResponse{
+				Field:   err.Field(),
+				Message: fieldMessage(err, locale, true),
+			})
+		}
+	}
+	return validationResponses
+}
+
+func fieldMessage(err validator.FieldError, locale string, withErrValidator bool) string {
+	key := "validation." + err.Tag()
+	param := err.Param()
+
+	switch err.Tag() {
+	case "required_if":
+		params := strings.Split(param, " ")
+		formattedParams := params[0]
+		for i, param := range params {
+			if i > 0 {
+				if i%2 != 0 {
+					formattedParams += fmt.Sprintf(" %s %s", i18n.Translate(locale, "validation.is"), param)
 				} else {
-					validationResponses = append(validationResponses, modelResp.ValidationResponse{
-						Field:   err.Field(),
-						Message: fmt.Sprintf("something wrong on %s; %s", err.Field(), err.Tag()),
-					})
+					formattedParams += fmt.Sprintf(" %s %s", i18n.Translate(locale, "validation.and"), param)
 				}
 			}
 		}
+		param = formattedParams
+	case "required_unless":
+		param = strings.Replace(param, " ", " "+i18n.Translate(locale, "validation.is_not")+" ", -1)
 	}
-	return validationResponses
+
+	if message, ok := i18n.Lookup(locale, key, err.Field(), param); ok {
+		return message
+	}
+	if message, ok := i18n.Lookup(i18n.DefaultLocale, key, err.Field(), param); ok {
+		return message
+	}
+
+	if errValidator, ok := ErrValidator[err.Tag()]; ok && withErrValidator {
+		if strings.Count(errValidator, "%s") == 1 {
+			return fmt.Sprintf(errValidator, err.Field())
+		}
+		return fmt.Sprintf(errValidator, err.Field(), err.Param())
+	}
+
+	return i18n.Translate(locale, "validation.default", err.Field(), err.Tag())
 }
@@ -88,3 +88,49 @@ func TestNewValidatorV2_ShouldSucceedToError(t *testing.T) {
 		}
 	})
 }
+
+func TestNewValidator_WithJSONFieldName(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		LoanId  int64  `uri:"loanId" validate:"required"`
+		Source  string `form:"source" validate:"required"`
+		Amount  int64  `json:"amount,omitempty" validate:"required"`
+		Partner string `json:"-" validate:"required"`
+	}
+
+	t.Run("Should Use JSON Field Name", func(t *testing.T) {
+		err := validator.New(validator.WithJSONFieldName()).Struct(request{})
+		require.Error(t, err)
+
+		require.Equal(t, []model.ValidationResponse{
+			{Field: "loanId", Message: "loanId is a required field"},
+			{Field: "source", Message: "source is a required field"},
+			{Field: "amount", Message: "amount is a required field"},
+			{Field: "Partner", Message: "Partner is a required field"},
+		}, validator.ToErrResponseV2(err))
+	})
+
+	t.Run("Should Share Validator", func(t *testing.T) {
+		require.Same(t, validator.Shared(), validator.Shared())
+	})
+}
+
+func TestToErrResponseLocale(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Should Use Indonesian Message", func(t *testing.T) {
+		err := validator.New().Struct(Person{Weight: "500", Url: "url", Height: 400})
+		require.Error(t, err)
+
+		require.Equal(t, []model.ValidationResponse{
+			{Field: "Name", Message: "Name wajib diisi jika Address kosong"},
+			{Field: "Address", Message: "Address wajib diisi"},
+			{Field: "Weight", Message: "Weight harus kurang dari atau sama dengan Height"},
+			{Field: "Url", Message: "Url harus berupa URL yang valid"},
+			{Field: "Height", Message: "panjang maksimal Height adalah 100"},
+			{Field: "Gender", Message: "Gender wajib diisi jika Url adalah url dan Height adalah 400"},
+			{Field: "Role", Message: "Role wajib diisi jika Url bukan uri"},
+		}, validator.ToErrResponseLocale(err, "id-ID"))
+	})
+}